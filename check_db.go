@@ -23,7 +23,7 @@ func main() {
 	defer conn.Close(ctx)
 
 	if *fix {
-		tag, err := conn.Exec(ctx, "UPDATE outbox SET status = 'new' WHERE status = 'processing'")
+		tag, err := conn.Exec(ctx, "UPDATE outbox SET status = 'new', claimed_by = NULL, lease_expires_at = NULL WHERE status = 'processing'")
 		if err != nil {
 			fmt.Printf("Fix failed: %v\n", err)
 		} else {
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,10 +13,14 @@ import (
 	"project/internal/api"
 	"project/internal/application/factories/infrastructure"
 	"project/internal/config"
-	"project/internal/grpc"
+	grpcApi "project/internal/grpc"
+	"project/internal/infrastructure/kafka"
 	"project/internal/infrastructure/postgres"
 	redisInfra "project/internal/infrastructure/redis"
+	"project/internal/saga"
 	"project/internal/usecase"
+
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -58,21 +63,54 @@ func main() {
 	inboxRepo := postgres.NewInboxRepository(pgPool)
 	paymentRepo := postgres.NewPaymentRepository(pgPool)
 	ticketRepo := postgres.NewTicketRepository(pgPool)
+	refundRepo := postgres.NewRefundRepository(pgPool)
+	deadLetterRepo := postgres.NewDeadLetterRepository(pgPool)
 	txManager := postgres.NewTxManager(pgPool)
 
+	booking, err := saga.LoadBooking(cfg.Saga)
+	if err != nil {
+		logger.Error("invalid saga definition", "error", err)
+		os.Exit(1)
+	}
+
 	// UseCases
-	createOrderUC := usecase.NewCreateOrder(txManager, orderRepo, outboxRepo)
+	createOrderUC := usecase.NewCreateOrder(txManager, orderRepo, outboxRepo, cfg.Saga.BookingMode)
 	getOrderUC := usecase.NewGetOrder(redisClient, orderRepo)
-	getWorkflowUC := usecase.NewGetWorkflow(orderRepo, outboxRepo, inboxRepo, paymentRepo, ticketRepo)
+	getWorkflowUC := usecase.NewGetWorkflow(orderRepo, outboxRepo, inboxRepo, paymentRepo, ticketRepo, refundRepo, booking)
 	refundOrderUC := usecase.NewRefundOrder(txManager, orderRepo, outboxRepo)
+	getOrderHistoryUC := usecase.NewGetOrderHistory(orderRepo)
+
+	// Order change notifications for WatchOrder
+	orderListener := postgres.NewOrderListener(infraFactory.PostgresConfig())
+	go orderListener.Run(ctx)
+	watchOrderUC := usecase.NewWatchOrder(orderRepo, outboxRepo, inboxRepo, orderListener)
 
-	// gRPC Server (Mocked start)
-	grpcService := grpc.NewServiceServer(createOrderUC)
-	_ = grpcService // In real app: pb.RegisterOrderServiceServer(grpcServer, grpcService)
+	// Kafka producer used to replay dead letters into their original topic
+	kafkaProd := kafka.NewProducer(kafka.Config{
+		Brokers: cfg.Kafka.Brokers,
+		Topic:   cfg.Kafka.Topic,
+	})
+	defer kafkaProd.Close()
+
+	listDeadLettersUC := usecase.NewListDeadLetters(deadLetterRepo)
+	getDeadLetterUC := usecase.NewGetDeadLetter(deadLetterRepo)
+	replayDeadLetterUC := usecase.NewReplayDeadLetter(deadLetterRepo, kafkaProd)
+	discardDeadLetterUC := usecase.NewDiscardDeadLetter(deadLetterRepo)
+
+	// gRPC Server
+	grpcSrv := grpc.NewServer()
+	grpcApi.Register(grpcSrv, grpcApi.NewServiceServer(createOrderUC, getOrderUC, getWorkflowUC, refundOrderUC, watchOrderUC))
+
+	grpcLis, err := net.Listen("tcp", ":"+cfg.GRPC.Port)
+	if err != nil {
+		logger.Error("failed to listen for grpc", "error", err)
+		os.Exit(1)
+	}
 
 	// REST API Handler
-	handlers := api.NewHandlers(createOrderUC, getOrderUC, getWorkflowUC, refundOrderUC)
-	apiHandler := api.NewRouter(handlers, redisClient)
+	handlers := api.NewHandlers(createOrderUC, getOrderUC, getWorkflowUC, refundOrderUC, getOrderHistoryUC)
+	dlqHandlers := api.NewDeadLetterHandlers(listDeadLettersUC, getDeadLetterUC, replayDeadLetterUC, discardDeadLetterUC)
+	apiHandler := api.NewRouter(handlers, dlqHandlers, redisClient, cfg.HTTP.AdminToken)
 
 	srv := &http.Server{
 		Addr:    ":" + cfg.HTTP.Port,
@@ -87,12 +125,33 @@ func main() {
 		}
 	}()
 
+	go func() {
+		logger.Info("gRPC server starting", "port", cfg.GRPC.Port)
+		if err := grpcSrv.Serve(grpcLis); err != nil {
+			logger.Error("grpc serve failed", "error", err)
+			os.Exit(1)
+		}
+	}()
+
 	<-ctx.Done()
 	logger.Info("Shutting down server...")
 
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer shutdownCancel()
 
+	// WatchOrder streams only end when their client leaves, so they are cut
+	// once the timeout is up.
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcSrv.GracefulStop()
+		close(grpcStopped)
+	}()
+	select {
+	case <-grpcStopped:
+	case <-shutdownCtx.Done():
+		grpcSrv.Stop()
+	}
+
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		logger.Error("Server forced to shutdown", "error", err)
 		os.Exit(1)
@@ -2,7 +2,7 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math/rand"
@@ -14,25 +14,25 @@ import (
 
 	"project/internal/application/factories/infrastructure"
 	"project/internal/config"
+	"project/internal/consumer"
 	domainEvent "project/internal/domain/event"
+	"project/internal/domain/order"
 	"project/internal/infrastructure/kafka"
 	"project/internal/infrastructure/postgres"
+	"project/internal/saga"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+const consumerName = "order-service"
+
 var (
 	ordersProcessed = promauto.NewCounter(prometheus.CounterOpts{
 		Name: "consumer_orders_processed_total",
 		Help: "The total number of processed orders",
 	})
-	processingDuration = promauto.NewHistogram(prometheus.HistogramOpts{
-		Name:    "consumer_processing_duration_seconds",
-		Help:    "Time taken to process order",
-		Buckets: []float64{0.1, 0.5, 1, 2, 5},
-	})
 )
 
 func main() {
@@ -49,7 +49,6 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
-	// Metrics Server
 	// Metrics Server
 	go func() {
 		mux := http.NewServeMux()
@@ -68,119 +67,126 @@ func main() {
 		os.Exit(1)
 	}
 
-	inboxRepo := postgres.NewInboxRepository(pgPool)
 	orderRepo := postgres.NewOrderRepository(pgPool)
+	inboxRepo := postgres.NewInboxRepository(pgPool)
 
 	// Kafka Consumer
 	groupID := cfg.Kafka.GroupID
 	if groupID == "" {
 		groupID = "order-service"
 	}
-	kafkaConsumer := kafka.NewConsumer(cfg.Kafka.Brokers, cfg.Kafka.Topic, groupID)
+	kafkaConsumer := kafka.NewRoutedConsumer(cfg.Kafka.Brokers, infraFactory.KafkaRouter(), groupID)
 	defer kafkaConsumer.Close()
 
-	consumerName := "order-service"
-	logger.Info("Order Consumer Started", "consumer", consumerName, "group_id", groupID, "topic", cfg.Kafka.Topic, "brokers", cfg.Kafka.Brokers)
-
-	for {
-		msg, err := kafkaConsumer.FetchMessage(ctx)
-		if err != nil {
-			if ctx.Err() != nil {
-				logger.Info("Order Consumer stopping")
-				break
-			}
-			logger.Error("failed to fetch message", "error", err)
-			time.Sleep(1 * time.Second)
-			continue
-		}
-
-		// Retry Loop
-		const maxRetries = 5
-		for attempt := 0; attempt <= maxRetries; attempt++ {
-			if attempt > 0 {
-				backoff := time.Duration(1<<attempt) * time.Second
-				logger.Info("Retry attempt", "attempt", attempt, "max", maxRetries, "backoff", backoff)
-				time.Sleep(backoff)
-			}
+	dlqProducer := kafka.NewProducer(kafka.Config{
+		Brokers: cfg.Kafka.Brokers,
+		Topic:   cfg.Kafka.DLQTopic,
+	})
+	defer dlqProducer.Close()
+
+	rt := consumer.NewRuntime(consumer.Config{
+		Name:        consumerName,
+		Pool:        pgPool,
+		Kafka:       kafkaConsumer,
+		Inbox:       inboxRepo,
+		Outbox:      postgres.NewOutboxRepository(pgPool),
+		DeadLetters: consumer.NewDeadLetterHandler(dlqProducer, postgres.NewDeadLetterRepository(pgPool)),
+		Logger:      logger,
+	})
 
-			processErr := func() error {
-				started := time.Now()
-				var ev domainEvent.Message
-				if err := json.Unmarshal(msg.Value, &ev); err != nil {
-					// Not our envelope (or corrupt). Commit and move on.
-					logger.Error("failed to unmarshal event envelope", "error", err)
-					return nil
-				}
+	booking, err := saga.LoadBooking(cfg.Saga)
+	if err != nil {
+		logger.Error("invalid saga definition", "error", err)
+		os.Exit(1)
+	}
 
-				switch ev.Type {
-				case "PaymentAuthorized", "TicketIssued", "PaymentFailed":
-					// handled below
-				default:
-					return nil
-				}
+	// Each saga reply moves the order to the status the flow declares for it.
+	transitions := booking.StatusTransitions()
+	for eventType, status := range transitions {
+		rt.Handle(eventType, func(ctx context.Context, ev *consumer.Event) error {
+			// Simulate load (2-3s) to make the saga feel cascading
+			time.Sleep(2*time.Second + time.Duration(rand.Intn(1000))*time.Millisecond)
 
-				tx, err := pgPool.Begin(ctx)
-				if err != nil {
-					return fmt.Errorf("begin tx: %w", err)
-				}
-				defer tx.Rollback(ctx)
+			if err := updateStatus(ctx, orderRepo, ev, status); err != nil {
+				return err
+			}
 
-				isNew, err := inboxRepo.SaveIfNotExists(ctx, tx, consumerName, ev.ID, ev.Type, ev.CorrelationID)
-				if err != nil {
-					return fmt.Errorf("inbox save: %w", err)
-				}
+			ev.AfterCommit(func() {
+				ordersProcessed.Inc()
+				logger.Info("Order state updated", "type", ev.Type, "correlation_id", ev.CorrelationID, "event_id", ev.ID)
+			})
+			return nil
+		})
+	}
 
-				if !isNew {
-					if err := tx.Commit(ctx); err != nil {
-						return fmt.Errorf("commit noop tx: %w", err)
-					}
-					return nil
+	// A refund completes once both the ticket and the payment side confirmed it,
+	// in whichever order the confirmations arrive. Both handlers lock the order
+	// first: if the confirmations are handled concurrently, the second waits for
+	// the first to commit and then sees its inbox row, so exactly one of them
+	// completes the refund.
+	refundConfirmations := map[string]string{
+		domainEvent.TypeTicketCancelled: domainEvent.TypePaymentRefunded,
+		domainEvent.TypePaymentRefunded: domainEvent.TypeTicketCancelled,
+	}
+	for eventType, other := range refundConfirmations {
+		rt.Handle(eventType, func(ctx context.Context, ev *consumer.Event) error {
+			if err := orderRepo.Lock(ctx, ev.CorrelationID); err != nil {
+				if errors.Is(err, order.ErrNotFound) {
+					return consumer.Permanent(err)
 				}
+				return err
+			}
 
-				ctxWithTx := context.WithValue(ctx, "tx", tx)
-
-				// Simulate load (2-3s) to make the saga feel cascading
-				time.Sleep(2*time.Second + time.Duration(rand.Intn(1000))*time.Millisecond)
-
-				switch ev.Type {
-				case "PaymentAuthorized":
-					if err := orderRepo.UpdateStatus(ctxWithTx, ev.CorrelationID, "PAYMENT_AUTHORIZED"); err != nil {
-						return fmt.Errorf("update order status: %w", err)
-					}
-				case "TicketIssued":
-					if err := orderRepo.UpdateStatus(ctxWithTx, ev.CorrelationID, "TICKET_ISSUED"); err != nil {
-						return fmt.Errorf("update order status: %w", err)
-					}
-				case "PaymentFailed":
-					if err := orderRepo.UpdateStatus(ctxWithTx, ev.CorrelationID, "CANCELLED"); err != nil {
-						return fmt.Errorf("update order status: %w", err)
-					}
-				}
+			done, err := inboxRepo.HasProcessed(ctx, consumerName, ev.CorrelationID, other)
+			if err != nil {
+				return fmt.Errorf("check %s: %w", other, err)
+			}
+			if !done {
+				logger.Info("Refund waiting for confirmation", "correlation_id", ev.CorrelationID, "received", ev.Type, "waiting_for", other)
+				return nil
+			}
 
-				if err := tx.Commit(ctx); err != nil {
-					return fmt.Errorf("commit tx: %w", err)
-				}
+			if err := updateStatus(ctx, orderRepo, ev, order.StatusRefunded); err != nil {
+				return err
+			}
 
-				processingDuration.Observe(time.Since(started).Seconds())
+			ev.AfterCommit(func() {
 				ordersProcessed.Inc()
-				logger.Info("Order state updated", "type", ev.Type, "correlation_id", ev.CorrelationID, "event_id", ev.ID)
-				return nil
-			}()
+				logger.Info("Order refunded", "correlation_id", ev.CorrelationID, "event_id", ev.ID)
+			})
+			return nil
+		})
+	}
 
-			if processErr == nil {
-				if err := kafkaConsumer.CommitMessages(ctx, msg); err != nil {
-					logger.Error("failed to commit kafka message", "error", err)
-				}
-				break
-			}
+	logger.Info("Order Consumer Started", "consumer", consumerName, "group_id", groupID, "topic", cfg.Kafka.Topic, "brokers", cfg.Kafka.Brokers)
 
-			logger.Error("Processing failed", "error", processErr)
-			if attempt == maxRetries {
-				logger.Error("DLQ: Dropping message after retries", "retries", maxRetries, "error", processErr)
-				if err := kafkaConsumer.CommitMessages(ctx, msg); err != nil {
-					logger.Error("failed to commit drop to kafka", "error", err)
-				}
-			}
-		}
+	if err := rt.Run(ctx); err != nil {
+		logger.Error("Order Consumer stopped with error", "error", err)
+	}
+	logger.Info("Order Consumer stopped")
+}
+
+// updateStatus applies a saga transition caused by ev. A transition the state machine
+// rejects will not succeed on retry, so the event goes straight to the DLQ for inspection.
+// The exception is a late reply for an order the watchdog already timed out: the
+// compensation takes care of it, so it is only logged.
+func updateStatus(ctx context.Context, orderRepo *postgres.OrderRepository, ev *consumer.Event, status string) error {
+	err := orderRepo.UpdateStatus(ctx, ev.CorrelationID, order.StatusChange{
+		To:          status,
+		ChangedBy:   consumerName,
+		EventType:   ev.Type,
+		CausationID: ev.ID,
+	})
+	var transitionErr *order.TransitionError
+	if errors.As(err, &transitionErr) && order.IsAbandoned(transitionErr.From) {
+		slog.Info("Ignoring late reply for abandoned order", "type", ev.Type, "correlation_id", ev.CorrelationID, "status", transitionErr.From)
+		return nil
+	}
+	if errors.Is(err, order.ErrInvalidTransition) || errors.Is(err, order.ErrNotFound) {
+		return consumer.Permanent(fmt.Errorf("update order status: %w", err))
+	}
+	if err != nil {
+		return fmt.Errorf("update order status: %w", err)
 	}
+	return nil
 }
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"project/internal/application/factories/infrastructure"
+	"project/internal/config"
+	"project/internal/domain/deadletter"
+	"project/internal/infrastructure/kafka"
+	"project/internal/infrastructure/postgres"
+	"project/internal/usecase"
+)
+
+const usage = `Usage: dlq <command> [flags]
+
+Commands:
+  list     list dead letters (filters: -consumer, -type, -correlation-id, -status, -from, -to, -limit)
+  show     print a dead letter with its payload:  dlq show <id>
+  replay   re-publish dead letters to their topic:  dlq replay <id>...
+  discard  mark dead letters as discarded:  dlq discard <id>...
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg, err := config.New()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	infraFactory := infrastructure.NewFactory(cfg)
+	defer infraFactory.Close()
+
+	pgPool, err := infraFactory.Postgres(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to connect to postgres: %v\n", err)
+		os.Exit(1)
+	}
+
+	deadLetterRepo := postgres.NewDeadLetterRepository(pgPool)
+
+	cmd, args := os.Args[1], os.Args[2:]
+	switch cmd {
+	case "list":
+		err = runList(ctx, usecase.NewListDeadLetters(deadLetterRepo), args)
+	case "show":
+		err = runShow(ctx, usecase.NewGetDeadLetter(deadLetterRepo), args)
+	case "replay":
+		kafkaProd := kafka.NewProducer(kafka.Config{
+			Brokers: cfg.Kafka.Brokers,
+			Topic:   cfg.Kafka.Topic,
+		})
+		defer kafkaProd.Close()
+		err = forEachID(args, func(id string) error {
+			return usecase.NewReplayDeadLetter(deadLetterRepo, kafkaProd).Execute(ctx, id)
+		}, "replayed")
+	case "discard":
+		err = forEachID(args, func(id string) error {
+			return usecase.NewDiscardDeadLetter(deadLetterRepo).Execute(ctx, id)
+		}, "discarded")
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", cmd, err)
+		os.Exit(1)
+	}
+}
+
+func runList(ctx context.Context, uc *usecase.ListDeadLetters, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	consumer := fs.String("consumer", "", "consumer name, e.g. payment-service")
+	eventType := fs.String("type", "", "event type, e.g. OrderCreated")
+	correlationID := fs.String("correlation-id", "", "correlation (order) id")
+	status := fs.String("status", deadletter.StatusNew, "new, replayed, discarded or empty for all")
+	from := fs.String("from", "", "only dead letters created at or after this time (RFC3339)")
+	to := fs.String("to", "", "only dead letters created before this time (RFC3339)")
+	limit := fs.Int("limit", 100, "maximum number of rows")
+	fs.Parse(args)
+
+	filter := deadletter.Filter{
+		Consumer:      *consumer,
+		EventType:     *eventType,
+		CorrelationID: *correlationID,
+		Status:        *status,
+		Limit:         *limit,
+	}
+
+	var err error
+	if *from != "" {
+		if filter.From, err = time.Parse(time.RFC3339, *from); err != nil {
+			return fmt.Errorf("invalid -from: %w", err)
+		}
+	}
+	if *to != "" {
+		if filter.To, err = time.Parse(time.RFC3339, *to); err != nil {
+			return fmt.Errorf("invalid -to: %w", err)
+		}
+	}
+
+	messages, err := uc.Execute(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tCREATED\tCONSUMER\tTYPE\tCORRELATION\tSTATUS\tATTEMPTS\tERROR")
+	for _, m := range messages {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			m.ID, m.CreatedAt.Format(time.RFC3339), m.Consumer, m.EventType, m.CorrelationID, m.Status, m.Attempts, m.Error)
+	}
+	return tw.Flush()
+}
+
+func runShow(ctx context.Context, uc *usecase.GetDeadLetter, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected exactly one id")
+	}
+
+	m, err := uc.Execute(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+func forEachID(ids []string, fn func(id string) error, done string) error {
+	if len(ids) == 0 {
+		return fmt.Errorf("expected at least one id")
+	}
+
+	for _, id := range ids {
+		if err := fn(id); err != nil {
+			return err
+		}
+		fmt.Printf("%s %s\n", id, done)
+	}
+	return nil
+}
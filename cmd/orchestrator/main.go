@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"project/internal/application/factories/infrastructure"
+	"project/internal/config"
+	"project/internal/consumer"
+	"project/internal/infrastructure/kafka"
+	"project/internal/infrastructure/postgres"
+	"project/internal/orchestrator"
+	"project/internal/saga"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	slog.SetDefault(logger)
+
+	cfg, err := config.New()
+	if err != nil {
+		logger.Error("Failed to load config, using defaults", "error", err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	// Metrics Server
+	go func() {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		logger.Info("Orchestrator metrics listening on :9097")
+		http.ListenAndServe(":9097", mux)
+	}()
+
+	infraFactory := infrastructure.NewFactory(cfg)
+	defer infraFactory.Close()
+
+	pgPool, err := infraFactory.Postgres(ctx)
+	if err != nil {
+		logger.Error("failed to connect to postgres", "error", err)
+		os.Exit(1)
+	}
+
+	outboxRepo := postgres.NewOutboxRepository(pgPool)
+
+	groupID := cfg.Kafka.GroupID
+	if groupID == "" || groupID == "orders-consumer-group-1" {
+		groupID = orchestrator.Name
+	}
+	kafkaConsumer := kafka.NewRoutedConsumer(cfg.Kafka.Brokers, infraFactory.KafkaRouter(), groupID)
+	defer kafkaConsumer.Close()
+
+	dlqProducer := kafka.NewProducer(kafka.Config{
+		Brokers: cfg.Kafka.Brokers,
+		Topic:   cfg.Kafka.DLQTopic,
+	})
+	defer dlqProducer.Close()
+
+	rt := consumer.NewRuntime(consumer.Config{
+		Name:        orchestrator.Name,
+		Pool:        pgPool,
+		Kafka:       kafkaConsumer,
+		Inbox:       postgres.NewInboxRepository(pgPool),
+		Outbox:      outboxRepo,
+		DeadLetters: consumer.NewDeadLetterHandler(dlqProducer, postgres.NewDeadLetterRepository(pgPool)),
+		Logger:      logger,
+	})
+
+	booking, err := saga.LoadBooking(cfg.Saga)
+	if err == nil {
+		err = booking.RequireCommands()
+	}
+	if err != nil {
+		logger.Error("invalid saga definition", "error", err)
+		os.Exit(1)
+	}
+
+	orch := orchestrator.New(
+		booking,
+		postgres.NewTxManager(pgPool),
+		postgres.NewSagaInstanceRepository(pgPool),
+		outboxRepo,
+		orchestrator.Config{
+			CommandTimeout: cfg.Saga.CommandTimeout,
+			CommandRetries: cfg.Saga.CommandRetries,
+			ScanInterval:   cfg.Saga.ScanInterval,
+			ScanBatchSize:  cfg.Saga.ScanBatchSize,
+		},
+		logger,
+	)
+	orch.Register(rt)
+
+	go func() {
+		if err := orch.RunDeadlines(ctx); err != nil {
+			logger.Error("Deadline checker stopped with error", "error", err)
+		}
+	}()
+
+	logger.Info("Saga Orchestrator Started", "flow", booking.Name, "steps", len(booking.Steps), "consumer", orchestrator.Name, "group_id", groupID, "topic", cfg.Kafka.Topic, "brokers", cfg.Kafka.Brokers)
+
+	if err := rt.Run(ctx); err != nil {
+		logger.Error("Saga Orchestrator stopped with error", "error", err)
+	}
+	logger.Info("Saga Orchestrator stopped")
+}
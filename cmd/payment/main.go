@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
 	"math/rand"
@@ -14,9 +13,9 @@ import (
 
 	"project/internal/application/factories/infrastructure"
 	"project/internal/config"
+	"project/internal/consumer"
 	domainEvent "project/internal/domain/event"
 	"project/internal/domain/order"
-	"project/internal/domain/outbox"
 	"project/internal/domain/payment"
 	"project/internal/infrastructure/kafka"
 	"project/internal/infrastructure/postgres"
@@ -32,19 +31,12 @@ var (
 		Name: "payment_service_events_processed_total",
 		Help: "The total number of processed events by payment service",
 	})
+	paymentsDeclined = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "payment_service_payments_declined_total",
+		Help: "The total number of declined payments by reason code",
+	}, []string{"reason_code"})
 )
 
-type paymentAuthorizedPayload struct {
-	OrderID    string  `json:"order_id"`
-	PaymentID  string  `json:"payment_id"`
-	Amount     float64 `json:"amount"`
-	FromCity   string  `json:"from_city"`
-	ToCity     string  `json:"to_city"`
-	TravelDate string  `json:"travel_date"`
-	TravelTime string  `json:"travel_time"`
-	Airline    string  `json:"airline"`
-}
-
 func main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 	slog.SetDefault(logger)
@@ -73,145 +65,296 @@ func main() {
 		os.Exit(1)
 	}
 
-	inboxRepo := postgres.NewInboxRepository(pgPool)
-	outboxRepo := postgres.NewOutboxRepository(pgPool)
 	paymentRepo := postgres.NewPaymentRepository(pgPool)
+	ledgerRepo := postgres.NewLedgerRepository(pgPool)
+	refundRepo := postgres.NewRefundRepository(pgPool)
+
+	policy := payment.Chain{
+		payment.AmountLimit{Max: cfg.Payment.MaxAmount},
+		payment.FakeGateway{DeclineCents: cfg.Payment.GatewayDeclineCents},
+	}
+	if cfg.Payment.CheckBalance {
+		policy = append(policy, payment.SufficientBalance{Ledger: ledgerRepo})
+	}
 
 	groupID := cfg.Kafka.GroupID
 	if groupID == "" || groupID == "orders-consumer-group-1" {
 		groupID = "payment-service"
 	}
-	kafkaConsumer := kafka.NewConsumer(cfg.Kafka.Brokers, cfg.Kafka.Topic, groupID)
+	kafkaConsumer := kafka.NewRoutedConsumer(cfg.Kafka.Brokers, infraFactory.KafkaRouter(), groupID)
 	defer kafkaConsumer.Close()
 
+	dlqProducer := kafka.NewProducer(kafka.Config{
+		Brokers: cfg.Kafka.Brokers,
+		Topic:   cfg.Kafka.DLQTopic,
+	})
+	defer dlqProducer.Close()
+
 	consumerName := "payment-service"
-	logger.Info("Payment Service Started", "consumer", consumerName, "group_id", groupID, "topic", cfg.Kafka.Topic, "brokers", cfg.Kafka.Brokers)
+	rt := consumer.NewRuntime(consumer.Config{
+		Name:        consumerName,
+		Pool:        pgPool,
+		Kafka:       kafkaConsumer,
+		Inbox:       postgres.NewInboxRepository(pgPool),
+		Outbox:      postgres.NewOutboxRepository(pgPool),
+		DeadLetters: consumer.NewDeadLetterHandler(dlqProducer, postgres.NewDeadLetterRepository(pgPool)),
+		Logger:      logger,
+	})
 
-	for {
-		msg, err := kafkaConsumer.FetchMessage(ctx)
+	// replyExisting answers a payment command with the outcome of the payment
+	// the order already has. Settled payments (voided, refunded) get no reply.
+	replyExisting := func(ev *consumer.Event, o domainEvent.OrderCreated, existing *payment.Payment) error {
+		switch existing.Status {
+		case payment.StatusAuthorized:
+			return ev.Emit(domainEvent.TypePaymentAuthorized, domainEvent.PaymentAuthorized{
+				OrderID:    o.OrderID,
+				PaymentID:  existing.ID,
+				Amount:     existing.Amount,
+				FromCity:   o.FromCity,
+				ToCity:     o.ToCity,
+				TravelDate: o.TravelDate,
+				TravelTime: o.TravelTime,
+				Airline:    o.Airline,
+				SagaMode:   o.SagaMode,
+			})
+		case payment.StatusFailed:
+			return ev.Emit(domainEvent.TypePaymentFailed, domainEvent.PaymentFailed{
+				OrderID:    o.OrderID,
+				PaymentID:  existing.ID,
+				Amount:     existing.Amount,
+				ReasonCode: existing.FailureReason,
+			})
+		}
+		logger.Info("Payment already settled", "order_id", o.OrderID, "payment_id", existing.ID, "status", existing.Status)
+		return nil
+	}
+
+	authorize := func(ctx context.Context, ev *consumer.Event, o domainEvent.OrderCreated) error {
+		// Simulate load (2-3s) to show cascading steps in UI
+		time.Sleep(2*time.Second + time.Duration(rand.Intn(1000))*time.Millisecond)
+
+		// Concurrent authorizations of one user would both pass the balance
+		// check before either holds the funds; serialize them until commit.
+		if err := ledgerRepo.LockUser(ctx, o.UserID); err != nil {
+			return err
+		}
+
+		decision, err := policy.Authorize(ctx, payment.AuthorizationRequest{
+			OrderID: o.OrderID,
+			UserID:  o.UserID,
+			Amount:  o.TotalAmount,
+		})
 		if err != nil {
-			if ctx.Err() != nil {
-				logger.Info("Payment Service stopping")
-				break
-			}
-			logger.Error("failed to fetch message", "error", err)
-			time.Sleep(1 * time.Second)
-			continue
+			return fmt.Errorf("authorize payment: %w", err)
+		}
+
+		paymentID := uuid.New().String()
+		p := &payment.Payment{
+			ID:        paymentID,
+			OrderID:   o.OrderID,
+			Status:    payment.StatusAuthorized,
+			Amount:    o.TotalAmount,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		if !decision.Approved {
+			p.Status = payment.StatusFailed
+			p.FailureReason = decision.ReasonCode
 		}
 
-		const maxRetries = 5
-		for attempt := 0; attempt <= maxRetries; attempt++ {
-			if attempt > 0 {
-				backoff := time.Duration(1<<attempt) * time.Second
-				logger.Info("Retry attempt", "attempt", attempt, "max", maxRetries, "backoff", backoff)
-				time.Sleep(backoff)
+		created, err := paymentRepo.Create(ctx, p)
+		if err != nil {
+			return fmt.Errorf("create payment: %w", err)
+		}
+		if !created {
+			// Another delivery of the command already paid for the order; report
+			// that payment rather than the one decided here.
+			existing, err := paymentRepo.GetByOrderID(ctx, o.OrderID)
+			if err != nil {
+				return err
+			}
+			if existing == nil {
+				return fmt.Errorf("payment of order %s conflicts but does not exist", o.OrderID)
 			}
+			return replyExisting(ev, o, existing)
+		}
 
-			processErr := func() error {
-				var ev domainEvent.Message
-				if err := json.Unmarshal(msg.Value, &ev); err != nil {
-					logger.Error("failed to unmarshal event envelope", "error", err)
-					return nil
-				}
-
-				if ev.Type != "OrderCreated" {
-					return nil
-				}
-
-				logger.Info("Received event", "type", ev.Type, "correlation_id", ev.CorrelationID, "event_id", ev.ID)
-
-				tx, err := pgPool.Begin(ctx)
-				if err != nil {
-					return fmt.Errorf("begin tx: %w", err)
-				}
-				defer tx.Rollback(ctx)
-
-				isNew, err := inboxRepo.SaveIfNotExists(ctx, tx, consumerName, ev.ID, ev.Type, ev.CorrelationID)
-				if err != nil {
-					return fmt.Errorf("inbox save: %w", err)
-				}
-
-				if !isNew {
-					if err := tx.Commit(ctx); err != nil {
-						return fmt.Errorf("commit noop tx: %w", err)
-					}
-					return nil
-				}
-
-				var o order.Order
-				if err := json.Unmarshal(ev.Payload, &o); err != nil {
-					return fmt.Errorf("unmarshal order payload: %w", err)
-				}
-
-				// Simulate load (2-3s) to show cascading steps in UI
-				time.Sleep(2*time.Second + time.Duration(rand.Intn(1000))*time.Millisecond)
-
-				paymentID := uuid.New().String()
-				p := &payment.Payment{
-					ID:        paymentID,
-					OrderID:   o.ID,
-					Status:    "AUTHORIZED",
-					Amount:    o.TotalAmount,
-					CreatedAt: time.Now(),
-					UpdatedAt: time.Now(),
-				}
-
-				ctxWithTx := context.WithValue(ctx, "tx", tx)
-				if err := paymentRepo.Create(ctxWithTx, p); err != nil {
-					return fmt.Errorf("create payment: %w", err)
-				}
-
-				payload, err := json.Marshal(paymentAuthorizedPayload{
-					OrderID:    o.ID,
-					PaymentID:  paymentID,
-					Amount:     o.TotalAmount,
-					FromCity:   o.FromCity,
-					ToCity:     o.ToCity,
-					TravelDate: o.TravelDate,
-					TravelTime: o.TravelTime,
-					Airline:    o.Airline,
-				})
-				if err != nil {
-					return fmt.Errorf("marshal PaymentAuthorized payload: %w", err)
-				}
-
-				outboxEvent := &outbox.Event{
-					ID:            uuid.New().String(),
-					EventType:     "PaymentAuthorized",
-					Payload:       payload,
-					Status:        "new",
-					CorrelationID: o.ID,
-					CausationID:   ev.ID,
-					Producer:      consumerName,
-					CreatedAt:     time.Now(),
-				}
-				if err := outboxRepo.Create(ctxWithTx, outboxEvent); err != nil {
-					return fmt.Errorf("create outbox event: %w", err)
-				}
-
-				if err := tx.Commit(ctx); err != nil {
-					return fmt.Errorf("commit tx: %w", err)
-				}
+		if !decision.Approved {
+			if err := ev.Emit(domainEvent.TypePaymentFailed, domainEvent.PaymentFailed{
+				OrderID:    o.OrderID,
+				PaymentID:  paymentID,
+				Amount:     o.TotalAmount,
+				ReasonCode: decision.ReasonCode,
+				Reason:     decision.Reason,
+			}); err != nil {
+				return err
+			}
 
+			ev.AfterCommit(func() {
 				paymentsProcessed.Inc()
-				logger.Info("Payment authorized", "order_id", o.ID, "event_id", ev.ID, "payment_id", paymentID)
-				return nil
-			}()
-
-			if processErr == nil {
-				if err := kafkaConsumer.CommitMessages(ctx, msg); err != nil {
-					logger.Error("failed to commit kafka message", "error", err)
-				}
-				break
+				paymentsDeclined.WithLabelValues(decision.ReasonCode).Inc()
+				logger.Info("Payment declined", "order_id", o.OrderID, "event_id", ev.ID, "payment_id", paymentID, "reason_code", decision.ReasonCode, "reason", decision.Reason)
+			})
+			return nil
+		}
+
+		if err := ledgerRepo.Hold(ctx, o.UserID, o.OrderID, o.TotalAmount); err != nil {
+			return fmt.Errorf("hold funds: %w", err)
+		}
+
+		if err := ev.Emit(domainEvent.TypePaymentAuthorized, domainEvent.PaymentAuthorized{
+			OrderID:    o.OrderID,
+			PaymentID:  paymentID,
+			Amount:     o.TotalAmount,
+			FromCity:   o.FromCity,
+			ToCity:     o.ToCity,
+			TravelDate: o.TravelDate,
+			TravelTime: o.TravelTime,
+			Airline:    o.Airline,
+			SagaMode:   o.SagaMode,
+		}); err != nil {
+			return err
+		}
+
+		ev.AfterCommit(func() {
+			paymentsProcessed.Inc()
+			logger.Info("Payment authorized", "order_id", o.OrderID, "event_id", ev.ID, "payment_id", paymentID)
+		})
+		return nil
+	}
+
+	// Choreography: authorize as soon as the order is created.
+	consumer.HandleTyped(rt, domainEvent.TypeOrderCreated, func(ctx context.Context, ev *consumer.Event, o domainEvent.OrderCreated) error {
+		if o.SagaMode == order.SagaOrchestration {
+			return nil
+		}
+		return authorize(ctx, ev, o)
+	})
+
+	// Orchestration: the orchestrator resends the command when the reply is late,
+	// so an existing payment is answered with its outcome instead of paying twice.
+	consumer.HandleTyped(rt, domainEvent.TypeAuthorizePayment, func(ctx context.Context, ev *consumer.Event, o domainEvent.OrderCreated) error {
+		existing, err := paymentRepo.GetByOrderID(ctx, o.OrderID)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return authorize(ctx, ev, o)
+		}
+
+		return replyExisting(ev, o, existing)
+	})
+
+	// voidPayment releases the authorized funds of an order and reports PaymentVoided.
+	// With confirm, PaymentVoided is emitted even when there is nothing to void, so
+	// the orchestrator waiting for the reply can finish.
+	voidPayment := func(ctx context.Context, ev *consumer.Event, orderID, reason string, confirm bool) error {
+		p, err := paymentRepo.Transition(ctx, orderID, payment.StatusAuthorized, payment.StatusVoided)
+		if err != nil {
+			return fmt.Errorf("void payment: %w", err)
+		}
+		if p == nil {
+			// Nothing to compensate: the payment was never authorized or is already voided.
+			logger.Info("No authorized payment to void", "order_id", orderID, "event_id", ev.ID)
+			if confirm {
+				return ev.Emit(domainEvent.TypePaymentVoided, domainEvent.PaymentVoided{OrderID: orderID, Reason: reason})
 			}
+			return nil
+		}
+
+		if err := ledgerRepo.Release(ctx, orderID); err != nil {
+			return fmt.Errorf("release funds: %w", err)
+		}
+
+		if err := ev.Emit(domainEvent.TypePaymentVoided, domainEvent.PaymentVoided{
+			OrderID:   orderID,
+			PaymentID: p.ID,
+			Amount:    p.Amount,
+			Reason:    reason,
+		}); err != nil {
+			return err
+		}
+
+		ev.AfterCommit(func() {
+			paymentsProcessed.Inc()
+			logger.Info("Payment voided", "order_id", orderID, "event_id", ev.ID, "payment_id", p.ID, "reason_code", reason)
+		})
+		return nil
+	}
+
+	// Compensation: the ticket could not be issued, so release the authorized funds.
+	// In orchestration the orchestrator decides on compensation and sends VoidPayment instead.
+	consumer.HandleTyped(rt, domainEvent.TypeTicketFailed, func(ctx context.Context, ev *consumer.Event, t domainEvent.TicketFailed) error {
+		if t.SagaMode == order.SagaOrchestration {
+			return nil
+		}
+		return voidPayment(ctx, ev, t.OrderID, t.ReasonCode, false)
+	})
+
+	// Timeouts from the saga watchdog. PaymentTimedOut covers a payment authorized
+	// after the order was already cancelled; TicketTimedOut is compensated like TicketFailed.
+	consumer.HandleTyped(rt, domainEvent.TypePaymentTimedOut, func(ctx context.Context, ev *consumer.Event, t domainEvent.SagaTimedOut) error {
+		if t.SagaMode == order.SagaOrchestration {
+			return nil
+		}
+		return voidPayment(ctx, ev, t.OrderID, "PAYMENT_TIMEOUT", false)
+	})
+	consumer.HandleTyped(rt, domainEvent.TypeTicketTimedOut, func(ctx context.Context, ev *consumer.Event, t domainEvent.SagaTimedOut) error {
+		if t.SagaMode == order.SagaOrchestration {
+			return nil
+		}
+		return voidPayment(ctx, ev, t.OrderID, "TICKET_TIMEOUT", false)
+	})
+
+	consumer.HandleTyped(rt, domainEvent.TypeVoidPayment, func(ctx context.Context, ev *consumer.Event, c domainEvent.Compensation) error {
+		return voidPayment(ctx, ev, c.OrderID, c.Reason, true)
+	})
 
-			logger.Error("Processing failed", "error", processErr)
-			if attempt == maxRetries {
-				logger.Error("DLQ: Dropping message after retries", "retries", maxRetries, "error", processErr)
-				if err := kafkaConsumer.CommitMessages(ctx, msg); err != nil {
-					logger.Error("failed to commit drop to kafka", "error", err)
-				}
+	// Refund: return the money for an authorized payment and confirm to the order service.
+	consumer.HandleTyped(rt, domainEvent.TypeRefundInitiated, func(ctx context.Context, ev *consumer.Event, r domainEvent.RefundInitiated) error {
+		p, err := paymentRepo.Transition(ctx, r.OrderID, payment.StatusAuthorized, payment.StatusRefunded)
+		if err != nil {
+			return fmt.Errorf("refund payment: %w", err)
+		}
+
+		// Confirm even when there is nothing to refund so the refund can complete.
+		payload := domainEvent.PaymentRefunded{OrderID: r.OrderID}
+		if p != nil {
+			refund := &payment.Refund{
+				ID:        uuid.New().String(),
+				PaymentID: p.ID,
+				OrderID:   r.OrderID,
+				Amount:    p.Amount,
+				Reason:    r.Reason,
+				CreatedAt: time.Now(),
+			}
+			if err := refundRepo.Create(ctx, refund); err != nil {
+				return fmt.Errorf("create refund: %w", err)
 			}
+			if err := ledgerRepo.Release(ctx, r.OrderID); err != nil {
+				return fmt.Errorf("release funds: %w", err)
+			}
+
+			payload.PaymentID = p.ID
+			payload.RefundID = refund.ID
+			payload.Amount = refund.Amount
 		}
+
+		if err := ev.Emit(domainEvent.TypePaymentRefunded, payload); err != nil {
+			return err
+		}
+
+		ev.AfterCommit(func() {
+			paymentsProcessed.Inc()
+			logger.Info("Payment refunded", "order_id", r.OrderID, "event_id", ev.ID, "payment_id", payload.PaymentID, "refund_id", payload.RefundID)
+		})
+		return nil
+	})
+
+	logger.Info("Payment Service Started", "consumer", consumerName, "group_id", groupID, "topic", cfg.Kafka.Topic, "brokers", cfg.Kafka.Brokers)
+
+	if err := rt.Run(ctx); err != nil {
+		logger.Error("Payment Service stopped with error", "error", err)
 	}
+	logger.Info("Payment Service stopped")
 }
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"project/internal/domain/event"
+)
+
+const usage = `Usage: schemas generate [-dir schemas/events]
+
+Writes the JSON Schema of every catalog event type and version. The committed
+schemas are checked by the tests of internal/domain/event (catalog_test.go).
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cmd, args := os.Args[1], os.Args[2:]
+	fset := flag.NewFlagSet(cmd, flag.ExitOnError)
+	dir := fset.String("dir", "schemas/events", "directory of the committed schemas")
+	fset.Parse(args)
+
+	var err error
+	switch cmd {
+	case "generate":
+		err = runGenerate(*dir)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", cmd, err)
+		os.Exit(1)
+	}
+}
+
+// runGenerate writes the current schemas. Files of older versions are kept:
+// they describe messages that may still be in Kafka or the DLQ.
+func runGenerate(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	for _, eventType := range event.Types() {
+		s, _ := event.Lookup(eventType)
+		if err := checkBreaking(dir, s); err != nil {
+			return err
+		}
+
+		data, err := s.MarshalJSONSchema()
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, s.FileName()), data, 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("%s written\n", s.FileName())
+	}
+	return nil
+}
+
+// checkBreaking compares s with the committed schema of the same version.
+func checkBreaking(dir string, s event.Schema) error {
+	data, err := os.ReadFile(filepath.Join(dir, s.FileName()))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var published event.JSONSchema
+	if err := json.Unmarshal(data, &published); err != nil {
+		return fmt.Errorf("%s: %w", s.FileName(), err)
+	}
+
+	changes := event.BreakingChanges(&published, s.JSONSchema())
+	if len(changes) == 0 {
+		return nil
+	}
+	msg := fmt.Sprintf("%s: breaking change, bump the %s version in the event catalog:", s.FileName(), s.Type)
+	for _, c := range changes {
+		msg += "\n  " + c
+	}
+	return errors.New(msg)
+}
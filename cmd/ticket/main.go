@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
 	"math/rand"
@@ -14,8 +13,9 @@ import (
 
 	"project/internal/application/factories/infrastructure"
 	"project/internal/config"
+	"project/internal/consumer"
 	domainEvent "project/internal/domain/event"
-	"project/internal/domain/outbox"
+	"project/internal/domain/order"
 	"project/internal/domain/ticket"
 	"project/internal/infrastructure/kafka"
 	"project/internal/infrastructure/postgres"
@@ -31,24 +31,12 @@ var (
 		Name: "ticket_service_events_processed_total",
 		Help: "The total number of processed events by ticket service",
 	})
+	ticketsFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ticket_service_tickets_failed_total",
+		Help: "The total number of failed ticket bookings by reason code",
+	}, []string{"reason_code"})
 )
 
-type paymentAuthorizedPayload struct {
-	OrderID    string  `json:"order_id"`
-	PaymentID  string  `json:"payment_id"`
-	Amount     float64 `json:"amount"`
-	FromCity   string  `json:"from_city"`
-	ToCity     string  `json:"to_city"`
-	TravelDate string  `json:"travel_date"`
-	TravelTime string  `json:"travel_time"`
-	Airline    string  `json:"airline"`
-}
-
-type ticketIssuedPayload struct {
-	OrderID  string `json:"order_id"`
-	TicketID string `json:"ticket_id"`
-}
-
 func main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 	slog.SetDefault(logger)
@@ -77,140 +65,205 @@ func main() {
 		os.Exit(1)
 	}
 
-	inboxRepo := postgres.NewInboxRepository(pgPool)
-	outboxRepo := postgres.NewOutboxRepository(pgPool)
 	ticketRepo := postgres.NewTicketRepository(pgPool)
 
+	airline := ticket.FakeAirline{
+		Seats:     ticketRepo,
+		Capacity:  cfg.Ticket.SeatsPerFlight,
+		Rejecting: cfg.Ticket.RejectingAirlines,
+	}
+
 	groupID := cfg.Kafka.GroupID
 	if groupID == "" || groupID == "orders-consumer-group-1" {
 		groupID = "ticket-service"
 	}
-	kafkaConsumer := kafka.NewConsumer(cfg.Kafka.Brokers, cfg.Kafka.Topic, groupID)
+	kafkaConsumer := kafka.NewRoutedConsumer(cfg.Kafka.Brokers, infraFactory.KafkaRouter(), groupID)
 	defer kafkaConsumer.Close()
 
+	dlqProducer := kafka.NewProducer(kafka.Config{
+		Brokers: cfg.Kafka.Brokers,
+		Topic:   cfg.Kafka.DLQTopic,
+	})
+	defer dlqProducer.Close()
+
 	consumerName := "ticket-service"
-	logger.Info("Ticket Service Started", "consumer", consumerName, "group_id", groupID, "topic", cfg.Kafka.Topic, "brokers", cfg.Kafka.Brokers)
+	rt := consumer.NewRuntime(consumer.Config{
+		Name:        consumerName,
+		Pool:        pgPool,
+		Kafka:       kafkaConsumer,
+		Inbox:       postgres.NewInboxRepository(pgPool),
+		Outbox:      postgres.NewOutboxRepository(pgPool),
+		DeadLetters: consumer.NewDeadLetterHandler(dlqProducer, postgres.NewDeadLetterRepository(pgPool)),
+		Logger:      logger,
+	})
 
-	for {
-		msg, err := kafkaConsumer.FetchMessage(ctx)
+	// replyExisting answers a ticket command with the outcome of the ticket the
+	// order already has. Settled tickets (refunded) get no reply.
+	replyExisting := func(ev *consumer.Event, p domainEvent.PaymentAuthorized, existing *ticket.Ticket) error {
+		switch existing.Status {
+		case ticket.StatusIssued:
+			return ev.Emit(domainEvent.TypeTicketIssued, domainEvent.TicketIssued{OrderID: p.OrderID, TicketID: existing.ID})
+		case ticket.StatusFailed:
+			return ev.Emit(domainEvent.TypeTicketFailed, domainEvent.TicketFailed{
+				OrderID:    p.OrderID,
+				TicketID:   existing.ID,
+				PaymentID:  p.PaymentID,
+				ReasonCode: existing.FailureReason,
+				SagaMode:   p.SagaMode,
+			})
+		}
+		logger.Info("Ticket already settled", "order_id", p.OrderID, "ticket_id", existing.ID, "status", existing.Status)
+		return nil
+	}
+
+	issue := func(ctx context.Context, ev *consumer.Event, p domainEvent.PaymentAuthorized) error {
+		// Simulate load (2-3s) to show cascading steps in UI
+		time.Sleep(2*time.Second + time.Duration(rand.Intn(1000))*time.Millisecond)
+
+		booking, err := airline.Book(ctx, ticket.Flight{
+			FromCity:   p.FromCity,
+			ToCity:     p.ToCity,
+			TravelDate: p.TravelDate,
+			TravelTime: p.TravelTime,
+			Airline:    p.Airline,
+		})
 		if err != nil {
-			if ctx.Err() != nil {
-				logger.Info("Ticket Service stopping")
-				break
-			}
-			logger.Error("failed to fetch message", "error", err)
-			time.Sleep(1 * time.Second)
-			continue
+			return fmt.Errorf("book seat: %w", err)
 		}
 
-		const maxRetries = 5
-		for attempt := 0; attempt <= maxRetries; attempt++ {
-			if attempt > 0 {
-				backoff := time.Duration(1<<attempt) * time.Second
-				logger.Info("Retry attempt", "attempt", attempt, "max", maxRetries, "backoff", backoff)
-				time.Sleep(backoff)
+		ticketID := uuid.New().String()
+		t := &ticket.Ticket{
+			ID:         ticketID,
+			OrderID:    p.OrderID,
+			FromCity:   p.FromCity,
+			ToCity:     p.ToCity,
+			TravelDate: p.TravelDate,
+			TravelTime: p.TravelTime,
+			Airline:    p.Airline,
+			Status:     ticket.StatusIssued,
+			CreatedAt:  time.Now(),
+			UpdatedAt:  time.Now(),
+		}
+		if !booking.Confirmed {
+			t.Status = ticket.StatusFailed
+			t.FailureReason = booking.ReasonCode
+		}
+
+		created, err := ticketRepo.Create(ctx, t)
+		if err != nil {
+			return fmt.Errorf("create ticket: %w", err)
+		}
+		if !created {
+			// Another delivery already issued the order's ticket; report that
+			// ticket rather than the one booked here.
+			existing, err := ticketRepo.GetByOrderID(ctx, p.OrderID)
+			if err != nil {
+				return err
+			}
+			if existing == nil {
+				return fmt.Errorf("ticket of order %s conflicts but does not exist", p.OrderID)
 			}
+			return replyExisting(ev, p, existing)
+		}
 
-			processErr := func() error {
-				var ev domainEvent.Message
-				if err := json.Unmarshal(msg.Value, &ev); err != nil {
-					logger.Error("failed to unmarshal event envelope", "error", err)
-					return nil
-				}
-
-				if ev.Type != "PaymentAuthorized" {
-					return nil
-				}
-
-				logger.Info("Received event", "type", ev.Type, "correlation_id", ev.CorrelationID, "event_id", ev.ID)
-
-				var p paymentAuthorizedPayload
-				if err := json.Unmarshal(ev.Payload, &p); err != nil {
-					return fmt.Errorf("unmarshal PaymentAuthorized payload: %w", err)
-				}
-
-				tx, err := pgPool.Begin(ctx)
-				if err != nil {
-					return fmt.Errorf("begin tx: %w", err)
-				}
-				defer tx.Rollback(ctx)
-
-				isNew, err := inboxRepo.SaveIfNotExists(ctx, tx, consumerName, ev.ID, ev.Type, ev.CorrelationID)
-				if err != nil {
-					return fmt.Errorf("inbox save: %w", err)
-				}
-
-				if !isNew {
-					if err := tx.Commit(ctx); err != nil {
-						return fmt.Errorf("commit noop tx: %w", err)
-					}
-					return nil
-				}
-
-				// Simulate load (2-3s) to show cascading steps in UI
-				time.Sleep(2*time.Second + time.Duration(rand.Intn(1000))*time.Millisecond)
-
-				ticketID := uuid.New().String()
-				t := &ticket.Ticket{
-					ID:         ticketID,
-					OrderID:    p.OrderID,
-					FromCity:   p.FromCity,
-					ToCity:     p.ToCity,
-					TravelDate: p.TravelDate,
-					TravelTime: p.TravelTime,
-					Airline:    p.Airline,
-					Status:     "ISSUED",
-					CreatedAt:  time.Now(),
-					UpdatedAt:  time.Now(),
-				}
-
-				ctxWithTx := context.WithValue(ctx, "tx", tx)
-				if err := ticketRepo.Create(ctxWithTx, t); err != nil {
-					return fmt.Errorf("create ticket: %w", err)
-				}
-
-				payload, err := json.Marshal(ticketIssuedPayload{OrderID: p.OrderID, TicketID: ticketID})
-				if err != nil {
-					return fmt.Errorf("marshal TicketIssued payload: %w", err)
-				}
-
-				outboxEvent := &outbox.Event{
-					ID:            uuid.New().String(),
-					EventType:     "TicketIssued",
-					Payload:       payload,
-					Status:        "new",
-					CorrelationID: p.OrderID,
-					CausationID:   ev.ID,
-					Producer:      consumerName,
-					CreatedAt:     time.Now(),
-				}
-				if err := outboxRepo.Create(ctxWithTx, outboxEvent); err != nil {
-					return fmt.Errorf("create outbox event: %w", err)
-				}
-
-				if err := tx.Commit(ctx); err != nil {
-					return fmt.Errorf("commit tx: %w", err)
-				}
+		if !booking.Confirmed {
+			// The payment is already authorized; TicketFailed makes the payment service void it.
+			if err := ev.Emit(domainEvent.TypeTicketFailed, domainEvent.TicketFailed{
+				OrderID:    p.OrderID,
+				TicketID:   ticketID,
+				PaymentID:  p.PaymentID,
+				ReasonCode: booking.ReasonCode,
+				Reason:     booking.Reason,
+				SagaMode:   p.SagaMode,
+			}); err != nil {
+				return err
+			}
 
+			ev.AfterCommit(func() {
 				ticketsProcessed.Inc()
-				logger.Info("Ticket issued", "order_id", p.OrderID, "ticket_id", ticketID, "event_id", ev.ID)
-				return nil
-			}()
-
-			if processErr == nil {
-				if err := kafkaConsumer.CommitMessages(ctx, msg); err != nil {
-					logger.Error("failed to commit kafka message", "error", err)
-				}
-				break
-			}
+				ticketsFailed.WithLabelValues(booking.ReasonCode).Inc()
+				logger.Info("Ticket failed", "order_id", p.OrderID, "ticket_id", ticketID, "event_id", ev.ID, "reason_code", booking.ReasonCode, "reason", booking.Reason)
+			})
+			return nil
+		}
 
-			logger.Error("Processing failed", "error", processErr)
-			if attempt == maxRetries {
-				logger.Error("DLQ: Dropping message after retries", "retries", maxRetries, "error", processErr)
-				if err := kafkaConsumer.CommitMessages(ctx, msg); err != nil {
-					logger.Error("failed to commit drop to kafka", "error", err)
-				}
-			}
+		if err := ev.Emit(domainEvent.TypeTicketIssued, domainEvent.TicketIssued{OrderID: p.OrderID, TicketID: ticketID}); err != nil {
+			return err
 		}
+
+		ev.AfterCommit(func() {
+			ticketsProcessed.Inc()
+			logger.Info("Ticket issued", "order_id", p.OrderID, "ticket_id", ticketID, "event_id", ev.ID)
+		})
+		return nil
+	}
+
+	// Choreography: book as soon as the payment is authorized.
+	consumer.HandleTyped(rt, domainEvent.TypePaymentAuthorized, func(ctx context.Context, ev *consumer.Event, p domainEvent.PaymentAuthorized) error {
+		if p.SagaMode == order.SagaOrchestration {
+			return nil
+		}
+		return issue(ctx, ev, p)
+	})
+
+	// Orchestration: a resent command is answered with the outcome of the existing ticket.
+	consumer.HandleTyped(rt, domainEvent.TypeIssueTicket, func(ctx context.Context, ev *consumer.Event, p domainEvent.PaymentAuthorized) error {
+		existing, err := ticketRepo.GetByOrderID(ctx, p.OrderID)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return issue(ctx, ev, p)
+		}
+
+		return replyExisting(ev, p, existing)
+	})
+
+	// Compensation: the payment was voided (e.g. the ticket step timed out), so a
+	// ticket issued late must not stay valid.
+	consumer.HandleTyped(rt, domainEvent.TypePaymentVoided, func(ctx context.Context, ev *consumer.Event, p domainEvent.PaymentVoided) error {
+		t, err := ticketRepo.Transition(ctx, p.OrderID, ticket.StatusIssued, ticket.StatusCancelled)
+		if err != nil {
+			return fmt.Errorf("cancel ticket: %w", err)
+		}
+		if t == nil {
+			return nil
+		}
+
+		ev.AfterCommit(func() {
+			ticketsProcessed.Inc()
+			logger.Info("Ticket cancelled after payment void", "order_id", p.OrderID, "ticket_id", t.ID, "event_id", ev.ID)
+		})
+		return nil
+	})
+
+	// Refund: cancel the issued ticket and confirm to the order service.
+	consumer.HandleTyped(rt, domainEvent.TypeRefundInitiated, func(ctx context.Context, ev *consumer.Event, r domainEvent.RefundInitiated) error {
+		t, err := ticketRepo.Transition(ctx, r.OrderID, ticket.StatusIssued, ticket.StatusCancelled)
+		if err != nil {
+			return fmt.Errorf("cancel ticket: %w", err)
+		}
+
+		// Confirm even when there is no issued ticket so the refund can complete.
+		payload := domainEvent.TicketCancelled{OrderID: r.OrderID}
+		if t != nil {
+			payload.TicketID = t.ID
+		}
+		if err := ev.Emit(domainEvent.TypeTicketCancelled, payload); err != nil {
+			return err
+		}
+
+		ev.AfterCommit(func() {
+			ticketsProcessed.Inc()
+			logger.Info("Ticket cancelled", "order_id", r.OrderID, "ticket_id", payload.TicketID, "event_id", ev.ID)
+		})
+		return nil
+	})
+
+	logger.Info("Ticket Service Started", "consumer", consumerName, "group_id", groupID, "topic", cfg.Kafka.Topic, "brokers", cfg.Kafka.Brokers)
+
+	if err := rt.Run(ctx); err != nil {
+		logger.Error("Ticket Service stopped with error", "error", err)
 	}
+	logger.Info("Ticket Service stopped")
 }
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"project/internal/application/factories/infrastructure"
+	"project/internal/config"
+	"project/internal/infrastructure/postgres"
+	"project/internal/saga"
+	"project/internal/worker"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	slog.SetDefault(logger)
+
+	cfg, err := config.New()
+	if err != nil {
+		logger.Error("failed to load config", "error", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	logger.Info(">>> STARTING SAGA WATCHDOG <<<")
+
+	// Metrics Server
+	go func() {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		logger.Info("Watchdog metrics listening on :9096")
+		http.ListenAndServe(":9096", mux)
+	}()
+
+	// Infrastructure
+	infraFactory := infrastructure.NewFactory(cfg)
+	defer infraFactory.Close()
+
+	pgPool, err := infraFactory.Postgres(ctx)
+	if err != nil {
+		logger.Error("failed to connect to postgres", "error", err)
+		os.Exit(1)
+	}
+
+	// Dependencies
+	txManager := postgres.NewTxManager(pgPool)
+	orderRepo := postgres.NewOrderRepository(pgPool)
+	outboxRepo := postgres.NewOutboxRepository(pgPool)
+	timeoutRepo := postgres.NewSagaTimeoutRepository(pgPool)
+
+	booking, err := saga.LoadBooking(cfg.Saga)
+	if err != nil {
+		logger.Error("invalid saga definition", "error", err)
+		os.Exit(1)
+	}
+
+	// A step times out while the order still has the status it had before the step.
+	var steps []worker.WatchdogStep
+	for i, s := range booking.Steps {
+		if s.Timeout > 0 {
+			steps = append(steps, worker.WatchdogStep{Status: booking.PendingStatus(i), Deadline: s.Timeout, EventType: s.TimeoutEvent})
+		}
+	}
+
+	w := worker.NewSagaWatchdog(txManager, orderRepo, outboxRepo, timeoutRepo, worker.WatchdogConfig{
+		Steps:        steps,
+		ScanInterval: cfg.Saga.ScanInterval,
+		BatchSize:    cfg.Saga.ScanBatchSize,
+	})
+
+	// Run
+	if err := w.Run(ctx); err != nil {
+		logger.Error("watchdog stopped with error", "error", err)
+	}
+
+	logger.Info("watchdog exited")
+}
@@ -28,7 +28,7 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
-	logger.Info(">>> STARTING NEW WORKER POLLER <<<")
+	logger.Info(">>> STARTING NEW WORKER <<<", "outbox_mode", cfg.Outbox.Mode, "require_all_acks", cfg.Outbox.RequireAllAcks)
 
 	// Infrastructure
 	infraFactory := infrastructure.NewFactory(cfg)
@@ -43,17 +43,61 @@ func main() {
 	// Dependencies
 	outboxRepo := postgres.NewOutboxRepository(pgPool)
 
+	router := infraFactory.KafkaRouter()
 	kafkaProd := kafka.NewProducer(kafka.Config{
-		Brokers: cfg.Kafka.Brokers,
-		Topic:   cfg.Kafka.Topic,
+		Brokers:        cfg.Kafka.Brokers,
+		Topic:          cfg.Kafka.Topic,
+		RequireAllAcks: cfg.Outbox.RequireAllAcks,
+		Router:         &router,
 	})
 	defer kafkaProd.Close()
 
-	// Worker (Poller)
-	w := worker.NewOutboxPoller(outboxRepo, kafkaProd)
+	codecs, err := infraFactory.EventCodecs()
+	if err != nil {
+		logger.Error("invalid event encoding", "error", err)
+		os.Exit(1)
+	}
+
+	// Relay: poll the table or stream inserts from a replication slot
+	var relay interface {
+		Run(ctx context.Context) error
+	}
+	switch cfg.Outbox.Mode {
+	case "cdc":
+		stream := postgres.NewOutboxStream(infraFactory.PostgresConfig(), postgres.OutboxStreamConfig{
+			SlotName:       cfg.Outbox.SlotName,
+			Publication:    cfg.Outbox.Publication,
+			StandbyTimeout: cfg.Outbox.StandbyTimeout,
+		})
+		relay = worker.NewOutboxCDCRelay(stream, outboxRepo, kafkaProd, worker.CDCConfig{
+			WorkerID:      cfg.Outbox.WorkerID,
+			LeaseDuration: cfg.Outbox.LeaseDuration,
+			Codecs:        codecs,
+		})
+	case "poll", "":
+		var listener *postgres.OutboxListener
+		if cfg.Outbox.Notify {
+			listener = postgres.NewOutboxListener(infraFactory.PostgresConfig())
+		}
+		partitionRepo := postgres.NewOutboxPartitionRepository(pgPool)
+		relay = worker.NewOutboxPoller(outboxRepo, partitionRepo, kafkaProd, listener, worker.PollerConfig{
+			WorkerID:        cfg.Outbox.WorkerID,
+			LeaseDuration:   cfg.Outbox.LeaseDuration,
+			ReapInterval:    cfg.Outbox.ReapInterval,
+			PollInterval:    cfg.Outbox.PollInterval,
+			BatchSize:       cfg.Outbox.BatchSize,
+			MaxDrainBatches: cfg.Outbox.MaxDrainBatches,
+			MaxInFlight:     cfg.Outbox.MaxInFlight,
+			PartitionLease:  cfg.Outbox.PartitionLease,
+			Codecs:          codecs,
+		})
+	default:
+		logger.Error("unknown outbox mode", "mode", cfg.Outbox.Mode)
+		os.Exit(1)
+	}
 
 	// Run
-	if err := w.Run(ctx); err != nil {
+	if err := relay.Run(ctx); err != nil {
 		logger.Error("worker stopped with error", "error", err)
 	}
 
@@ -30,6 +30,7 @@ function App() {
   const [activeOrder, setActiveOrder] = useState(null); // { order_id, done }
   const [alert, setAlert] = useState(null);
   const [loading, setLoading] = useState(false);
+  const [sagaMode, setSagaMode] = useState('choreography');
 
   const workflowRef = useRef(null);
 
@@ -53,6 +54,7 @@ function App() {
           date: ticket.date,
           time: ticket.time,
           airline: ticket.airline,
+          saga_mode: sagaMode,
         })
       });
 
@@ -141,6 +143,14 @@ function App() {
 
       <SearchForm onSearch={handleSearch} />
 
+      <div style={{ textAlign: 'center', margin: '10px 0', color: '#8b9bb4' }}>
+        Режим саги:{' '}
+        <select value={sagaMode} onChange={(e) => setSagaMode(e.target.value)}>
+          <option value="choreography">хореография</option>
+          <option value="orchestration">оркестрация</option>
+        </select>
+      </div>
+
       <TicketList tickets={tickets} onBuy={handleBuy} />
 
       <div ref={workflowRef} />
@@ -27,7 +27,29 @@ const ruEventType = (s) => {
     PaymentAuthorized: 'Оплата подтверждена',
     TicketIssued: 'Билет выпущен',
     PaymentFailed: 'Ошибка оплаты',
+    TicketFailed: 'Билет не выпущен',
+    PaymentVoided: 'Оплата отменена (компенсация)',
+    PaymentTimedOut: 'Таймаут оплаты',
+    TicketTimedOut: 'Таймаут выписки билета',
     RefundInitiated: 'Возврат инициирован',
+    TicketCancelled: 'Билет аннулирован',
+    PaymentRefunded: 'Деньги возвращены',
+    AuthorizePayment: 'Команда: авторизовать оплату',
+    IssueTicket: 'Команда: выписать билет',
+    VoidPayment: 'Команда: отменить оплату',
+  };
+  return map[s] || s || '—';
+};
+
+const ruProgress = (s) => {
+  const map = {
+    pending: 'не начат',
+    running: 'выполняется',
+    completed: 'выполнен',
+    failed: 'ошибка',
+    timed_out: 'таймаут',
+    compensating: 'компенсируется',
+    compensated: 'компенсирован',
   };
   return map[s] || s || '—';
 };
@@ -47,7 +69,10 @@ const ruOrderStatus = (s) => {
     PAYMENT_AUTHORIZED: 'Оплата подтверждена',
     TICKET_ISSUED: 'Билет оформлен',
     CANCELLED: 'Отменен',
+    COMPENSATING: 'Компенсация',
+    CANCELLED_COMPENSATED: 'Отменен (оплата возвращена)',
     REFUND_PENDING: 'Возврат в обработке',
+    REFUNDED: 'Возвращен',
   };
   return map[s] || s || '—';
 };
@@ -55,6 +80,8 @@ const ruOrderStatus = (s) => {
 const ruTicketStatus = (s) => {
   const map = {
     ISSUED: 'Выпущен',
+    FAILED: 'Не выпущен',
+    CANCELLED: 'Аннулирован',
   };
   return map[s] || s || '—';
 };
@@ -154,6 +181,7 @@ export default function Workflow({ orderId }) {
               <KV k="travel_date" v={order.travel_date} />
               <KV k="travel_time" v={order.travel_time} />
               <KV k="airline" v={order.airline} />
+              <KV k="saga_mode" v={order.saga_mode} />
               <KV k="created_at" v={order.created_at} />
             </div>
 
@@ -312,7 +340,9 @@ export default function Workflow({ orderId }) {
     <div className="card wf">
       <div className="wf-header">
         <div>
-          <div className="wf-title">Воркфлоу: Outbox + Inbox + Saga (хореография)</div>
+          <div className="wf-title">
+            Воркфлоу: Outbox + Inbox + Saga ({workflow?.order?.saga_mode === 'orchestration' ? 'оркестрация' : 'хореография'})
+          </div>
           <div className="wf-sub">
             Order ID: <span className="wf-mono">{orderId}</span>
           </div>
@@ -328,6 +358,19 @@ export default function Workflow({ orderId }) {
 
       {error && <div className="wf-error">{error}</div>}
 
+      {workflow?.progress?.length > 0 && (
+        <div className="wf-kv" style={{ marginBottom: 10 }}>
+          {workflow.progress.map((p) => (
+            <KV
+              key={p.step}
+              k={`${p.step} (${p.participant})`}
+              v={`${ruProgress(p.state)}${p.event ? ` — ${ruEventType(p.event)}` : ''}`}
+              raw={`ждем ${p.expected}`}
+            />
+          ))}
+        </div>
+      )}
+
       <div className="wf-steps">
         {steps.length === 0 ? (
           <div className="wf-empty">Ждем данные workflow...</div>
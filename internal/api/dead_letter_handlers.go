@@ -0,0 +1,140 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"project/internal/domain/deadletter"
+	"project/internal/usecase"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// DeadLetterHandlers serves the admin endpoints for inspecting and replaying dead letters.
+type DeadLetterHandlers struct {
+	listUC    *usecase.ListDeadLetters
+	getUC     *usecase.GetDeadLetter
+	replayUC  *usecase.ReplayDeadLetter
+	discardUC *usecase.DiscardDeadLetter
+}
+
+func NewDeadLetterHandlers(listUC *usecase.ListDeadLetters, getUC *usecase.GetDeadLetter, replayUC *usecase.ReplayDeadLetter, discardUC *usecase.DiscardDeadLetter) *DeadLetterHandlers {
+	return &DeadLetterHandlers{
+		listUC:    listUC,
+		getUC:     getUC,
+		replayUC:  replayUC,
+		discardUC: discardUC,
+	}
+}
+
+// List supports filtering by consumer, event_type, correlation_id, status,
+// from/to (RFC3339) and limit query parameters.
+func (h *DeadLetterHandlers) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := deadletter.Filter{
+		Consumer:      q.Get("consumer"),
+		EventType:     q.Get("event_type"),
+		CorrelationID: q.Get("correlation_id"),
+		Status:        q.Get("status"),
+	}
+
+	var err error
+	if v := q.Get("from"); v != "" {
+		if filter.From, err = time.Parse(time.RFC3339, v); err != nil {
+			http.Error(w, "invalid from: expected RFC3339", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if filter.To, err = time.Parse(time.RFC3339, v); err != nil {
+			http.Error(w, "invalid to: expected RFC3339", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit <= 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	messages, err := h.listUC.Execute(r.Context(), filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(messages)
+}
+
+func (h *DeadLetterHandlers) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := deadLetterID(w, r)
+	if !ok {
+		return
+	}
+
+	m, err := h.getUC.Execute(r.Context(), id)
+	if err != nil {
+		writeDeadLetterError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m)
+}
+
+func (h *DeadLetterHandlers) Replay(w http.ResponseWriter, r *http.Request) {
+	id, ok := deadLetterID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.replayUC.Execute(r.Context(), id); err != nil {
+		writeDeadLetterError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"status": deadletter.StatusReplayed})
+}
+
+func (h *DeadLetterHandlers) Discard(w http.ResponseWriter, r *http.Request) {
+	id, ok := deadLetterID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.discardUC.Execute(r.Context(), id); err != nil {
+		writeDeadLetterError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"status": deadletter.StatusDiscarded})
+}
+
+// deadLetterID returns the {id} URL parameter, or writes 400 if it is not a UUID
+// (the type of dead_letters.id) so that it never reaches the repository.
+func deadLetterID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := chi.URLParam(r, "id")
+	if err := uuid.Validate(id); err != nil {
+		http.Error(w, "invalid id: expected UUID", http.StatusBadRequest)
+		return "", false
+	}
+	return id, true
+}
+
+func writeDeadLetterError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, deadletter.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, deadletter.ErrDiscarded):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
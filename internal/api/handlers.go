@@ -2,37 +2,43 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"project/internal/domain/order"
 	"project/internal/usecase"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 )
 
 type Handlers struct {
-	createOrderUC *usecase.CreateOrder
-	getOrderUC    *usecase.GetOrder
-	getWorkflowUC *usecase.GetWorkflow
-	refundOrderUC *usecase.RefundOrder
+	createOrderUC     *usecase.CreateOrder
+	getOrderUC        *usecase.GetOrder
+	getWorkflowUC     *usecase.GetWorkflow
+	refundOrderUC     *usecase.RefundOrder
+	getOrderHistoryUC *usecase.GetOrderHistory
 }
 
-func NewHandlers(createOrderUC *usecase.CreateOrder, getOrderUC *usecase.GetOrder, getWorkflowUC *usecase.GetWorkflow, refundOrderUC *usecase.RefundOrder) *Handlers {
+func NewHandlers(createOrderUC *usecase.CreateOrder, getOrderUC *usecase.GetOrder, getWorkflowUC *usecase.GetWorkflow, refundOrderUC *usecase.RefundOrder, getOrderHistoryUC *usecase.GetOrderHistory) *Handlers {
 	return &Handlers{
-		createOrderUC: createOrderUC,
-		getOrderUC:    getOrderUC,
-		getWorkflowUC: getWorkflowUC,
-		refundOrderUC: refundOrderUC,
+		createOrderUC:     createOrderUC,
+		getOrderUC:        getOrderUC,
+		getWorkflowUC:     getWorkflowUC,
+		refundOrderUC:     refundOrderUC,
+		getOrderHistoryUC: getOrderHistoryUC,
 	}
 }
 
 func (h *Handlers) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		UserID  string  `json:"user_id"`
-		Amount  float64 `json:"amount"`
-		From    string  `json:"from"`
-		To      string  `json:"to"`
-		Date    string  `json:"date"`
-		Time    string  `json:"time"`
-		Airline string  `json:"airline"`
+		UserID   string  `json:"user_id"`
+		Amount   float64 `json:"amount"`
+		From     string  `json:"from"`
+		To       string  `json:"to"`
+		Date     string  `json:"date"`
+		Time     string  `json:"time"`
+		Airline  string  `json:"airline"`
+		SagaMode string  `json:"saga_mode"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -41,16 +47,21 @@ func (h *Handlers) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	}
 
 	params := usecase.CreateOrderParams{
-		UserID:  req.UserID,
-		Amount:  req.Amount,
-		From:    req.From,
-		To:      req.To,
-		Date:    req.Date,
-		Time:    req.Time,
-		Airline: req.Airline,
+		UserID:   req.UserID,
+		Amount:   req.Amount,
+		From:     req.From,
+		To:       req.To,
+		Date:     req.Date,
+		Time:     req.Time,
+		Airline:  req.Airline,
+		SagaMode: req.SagaMode,
 	}
 
 	id, err := h.createOrderUC.Execute(r.Context(), params)
+	if errors.Is(err, usecase.ErrInvalidSagaMode) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -64,15 +75,14 @@ func (h *Handlers) CreateOrder(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handlers) GetOrder(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		http.Error(w, "missing order id", http.StatusBadRequest)
+	id, ok := orderID(w, r)
+	if !ok {
 		return
 	}
 
 	order, err := h.getOrderUC.Execute(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeOrderError(w, err)
 		return
 	}
 
@@ -84,15 +94,14 @@ func (h *Handlers) GetOrder(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handlers) GetWorkflow(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		http.Error(w, "missing order id", http.StatusBadRequest)
+	id, ok := orderID(w, r)
+	if !ok {
 		return
 	}
 
 	workflow, err := h.getWorkflowUC.Execute(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeOrderError(w, err)
 		return
 	}
 
@@ -103,10 +112,26 @@ func (h *Handlers) GetWorkflow(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(workflow)
 }
 
+func (h *Handlers) GetOrderHistory(w http.ResponseWriter, r *http.Request) {
+	id, ok := orderID(w, r)
+	if !ok {
+		return
+	}
+
+	history, err := h.getOrderHistoryUC.Execute(r.Context(), id)
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	json.NewEncoder(w).Encode(history)
+}
+
 func (h *Handlers) RefundOrder(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	if id == "" {
-		http.Error(w, "missing order id", http.StatusBadRequest)
+	id, ok := orderID(w, r)
+	if !ok {
 		return
 	}
 
@@ -124,10 +149,33 @@ func (h *Handlers) RefundOrder(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.refundOrderUC.Execute(r.Context(), params); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeOrderError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusAccepted)
 	json.NewEncoder(w).Encode(map[string]string{"status": "refund_initiated"})
 }
+
+// writeOrderError maps order domain errors to HTTP status codes.
+func writeOrderError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, order.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, order.ErrInvalidTransition):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// orderID returns the {id} URL parameter, or writes 400 if it is not a UUID
+// so that a malformed id is not reported as a database error.
+func orderID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		http.Error(w, "invalid order id: expected UUID", http.StatusBadRequest)
+		return "", false
+	}
+	return id, true
+}
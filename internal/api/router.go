@@ -12,7 +12,10 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-func NewRouter(h *Handlers, redisClient *redis.Client) http.Handler {
+// NewRouter builds the HTTP API. The dead-letter admin endpoints require
+// "Authorization: Bearer <adminToken>" and are not registered at all when
+// adminToken is empty.
+func NewRouter(h *Handlers, dlq *DeadLetterHandlers, redisClient *redis.Client, adminToken string) http.Handler {
 	r := chi.NewRouter()
 
 	r.Use(ChiMiddleware.Logger)
@@ -35,13 +38,27 @@ func NewRouter(h *Handlers, redisClient *redis.Client) http.Handler {
 	// Cached Order Get
 	r.Get("/orders/{id}", h.GetOrder)
 	r.Get("/orders/{id}/workflow", h.GetWorkflow)
+	r.Get("/orders/{id}/history", h.GetOrderHistory)
 
 	// Refund Order (Idempotent by nature of state machine usually, but could add middleware)
 	r.Post("/orders/{id}/refund", h.RefundOrder)
 
+	// Dead-letter administration
+	if adminToken != "" {
+		r.Route("/admin/dead-letters", func(r chi.Router) {
+			r.Use(middleware.AdminToken(adminToken))
+			r.Get("/", dlq.List)
+			r.Get("/{id}", dlq.Get)
+			r.Post("/{id}/replay", dlq.Replay)
+			r.Post("/{id}/discard", dlq.Discard)
+		})
+	} else {
+		log.Println("ADMIN_TOKEN is not set: /admin/dead-letters is disabled")
+	}
+
 	r.Handle("/metrics", promhttp.Handler())
 
-	log.Println("Registered routes: POST /orders (Idempotent), GET /orders/{id} (Cached), GET /metrics")
+	log.Println("Registered routes: POST /orders (Idempotent), GET /orders/{id} (Cached), /admin/dead-letters (Bearer token), GET /metrics")
 
 	return r
 }
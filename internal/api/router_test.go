@@ -0,0 +1,71 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDeadLetterAdminRoutes(t *testing.T) {
+	// The use cases are nil: every request below must be answered before
+	// reaching them.
+	dlq := NewDeadLetterHandlers(nil, nil, nil, nil)
+
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		path   string
+		auth   string
+		want   int
+	}{
+		{"disabled without token", "", http.MethodPost, "/admin/dead-letters/0b7c1c5e-4f0e-4d55-9d41-2f1c8a3d9e01/replay", "Bearer x", http.StatusNotFound},
+		{"replay unauthenticated", "s3cret", http.MethodPost, "/admin/dead-letters/0b7c1c5e-4f0e-4d55-9d41-2f1c8a3d9e01/replay", "", http.StatusUnauthorized},
+		{"discard wrong token", "s3cret", http.MethodPost, "/admin/dead-letters/0b7c1c5e-4f0e-4d55-9d41-2f1c8a3d9e01/discard", "Bearer nope", http.StatusUnauthorized},
+		{"list unauthenticated", "s3cret", http.MethodGet, "/admin/dead-letters/", "", http.StatusUnauthorized},
+		{"get invalid id", "s3cret", http.MethodGet, "/admin/dead-letters/42", "Bearer s3cret", http.StatusBadRequest},
+		{"replay invalid id", "s3cret", http.MethodPost, "/admin/dead-letters/not-a-uuid/replay", "Bearer s3cret", http.StatusBadRequest},
+		{"discard invalid id", "s3cret", http.MethodPost, "/admin/dead-letters/not-a-uuid/discard", "Bearer s3cret", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := NewRouter(&Handlers{}, dlq, nil, tt.token)
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("%s %s = %d, want %d", tt.method, tt.path, rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidOrderID(t *testing.T) {
+	// The use cases are nil: an invalid id must be rejected before reaching them.
+	router := NewRouter(&Handlers{}, NewDeadLetterHandlers(nil, nil, nil, nil), nil, "")
+
+	routes := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/orders/%s"},
+		{http.MethodGet, "/orders/%s/workflow"},
+		{http.MethodGet, "/orders/%s/history"},
+		{http.MethodPost, "/orders/%s/refund"},
+	}
+	for _, id := range []string{"42", "not-a-uuid", "0b7c1c5e-4f0e-4d55-9d41"} {
+		for _, rt := range routes {
+			path := fmt.Sprintf(rt.path, id)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(rt.method, path, nil))
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("%s %s = %d, want %d", rt.method, path, rec.Code, http.StatusBadRequest)
+			}
+		}
+	}
+}
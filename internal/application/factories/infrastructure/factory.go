@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"project/internal/config"
+	"project/internal/infrastructure/eventcodec"
+	"project/internal/infrastructure/kafka"
 	"project/internal/infrastructure/postgres"
 	"project/internal/infrastructure/redis"
 
@@ -25,6 +27,28 @@ func NewFactory(cfg *config.Config) *Factory {
 	}
 }
 
+// PostgresConfig returns the connection settings shared by the pool and
+// replication connections.
+func (f *Factory) PostgresConfig() postgres.Config {
+	return postgres.Config{
+		Host:     f.cfg.Postgres.Host,
+		Port:     f.cfg.Postgres.Port,
+		User:     f.cfg.Postgres.User,
+		Password: f.cfg.Postgres.Password,
+		DBName:   f.cfg.Postgres.DBName,
+	}
+}
+
+// KafkaRouter maps event types to topics according to the kafka routes config.
+func (f *Factory) KafkaRouter() kafka.Router {
+	return kafka.NewRouter(f.cfg.Kafka.Topic, f.cfg.Kafka.Routes)
+}
+
+// EventCodecs returns the envelope codec of each topic written by the relay.
+func (f *Factory) EventCodecs() (*eventcodec.Selector, error) {
+	return eventcodec.NewSelector(f.cfg.Kafka.Encoding, f.cfg.Kafka.TopicEncodings)
+}
+
 func (f *Factory) Postgres(ctx context.Context) (*pgxpool.Pool, error) {
 	if f.pgPool != nil {
 		return f.pgPool, nil
@@ -35,13 +59,7 @@ func (f *Factory) Postgres(ctx context.Context) (*pgxpool.Pool, error) {
 
 	// Retry connection up to 5 times
 	for i := 0; i < 5; i++ {
-		pool, err = postgres.NewClient(ctx, postgres.Config{
-			Host:     f.cfg.Postgres.Host,
-			Port:     f.cfg.Postgres.Port,
-			User:     f.cfg.Postgres.User,
-			Password: f.cfg.Postgres.Password,
-			DBName:   f.cfg.Postgres.DBName,
-		})
+		pool, err = postgres.NewClient(ctx, f.PostgresConfig())
 		if err == nil {
 			break
 		}
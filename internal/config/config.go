@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -9,10 +10,15 @@ import (
 type Config struct {
 	App      App      `yaml:"app"`
 	HTTP     HTTP     `yaml:"http"`
+	GRPC     GRPC     `yaml:"grpc"`
 	Log      Log      `yaml:"log"`
 	Postgres Postgres `yaml:"postgres"`
 	Redis    Redis    `yaml:"redis"`
 	Kafka    Kafka    `yaml:"kafka"`
+	Outbox   Outbox   `yaml:"outbox"`
+	Payment  Payment  `yaml:"payment"`
+	Ticket   Ticket   `yaml:"ticket"`
+	Saga     Saga     `yaml:"saga"`
 }
 
 type App struct {
@@ -22,6 +28,14 @@ type App struct {
 
 type HTTP struct {
 	Port string `yaml:"port" env:"HTTP_PORT" env-default:"8080"`
+	// AdminToken is the Bearer token of the /admin endpoints; they are not
+	// served when it is empty.
+	AdminToken string `yaml:"admin_token" env:"ADMIN_TOKEN"`
+}
+
+// GRPC is the listener of the OrderService in cmd/api.
+type GRPC struct {
+	Port string `yaml:"port" env:"GRPC_PORT" env-default:"50051"`
 }
 
 type Log struct {
@@ -41,9 +55,79 @@ type Redis struct {
 }
 
 type Kafka struct {
-	Brokers []string `yaml:"brokers" env:"KAFKA_BROKERS" env-default:"localhost:9092"`
-	Topic   string   `yaml:"topic" env:"KAFKA_TOPIC" env-default:"orders-events"`
-	GroupID string   `yaml:"group_id" env:"KAFKA_GROUP_ID" env-default:"orders-consumer-group-1"`
+	Brokers  []string `yaml:"brokers" env:"KAFKA_BROKERS" env-default:"localhost:9092"`
+	Topic    string   `yaml:"topic" env:"KAFKA_TOPIC" env-default:"orders-events"`
+	GroupID  string   `yaml:"group_id" env:"KAFKA_GROUP_ID" env-default:"orders-consumer-group-1"`
+	DLQTopic string   `yaml:"dlq_topic" env:"KAFKA_DLQ_TOPIC" env-default:"orders-events.dlq"`
+	// Routes moves individual event types off Topic, e.g. "OrderCreated:orders.created".
+	// A routed type is no longer ordered with the other events of its order.
+	Routes map[string]string `yaml:"routes" env:"KAFKA_ROUTES"`
+	// Encoding is the event envelope codec written by the relay: json or protobuf.
+	Encoding string `yaml:"encoding" env:"KAFKA_ENCODING" env-default:"json"`
+	// TopicEncodings overrides Encoding per topic, e.g. "orders-events:protobuf".
+	TopicEncodings map[string]string `yaml:"topic_encodings" env:"KAFKA_TOPIC_ENCODINGS"`
+}
+
+type Outbox struct {
+	// Mode selects the relay: poll (claim batches from the table) or cdc (logical replication stream).
+	Mode string `yaml:"mode" env:"OUTBOX_MODE" env-default:"poll"`
+	// WorkerID identifies the relay instance holding a lease; defaults to hostname-pid.
+	WorkerID      string        `yaml:"worker_id" env:"OUTBOX_WORKER_ID"`
+	LeaseDuration time.Duration `yaml:"lease_duration" env:"OUTBOX_LEASE_DURATION" env-default:"30s"`
+	ReapInterval  time.Duration `yaml:"reap_interval" env:"OUTBOX_REAP_INTERVAL" env-default:"10s"`
+	// Notify wakes the poller through LISTEN/NOTIFY; PollInterval remains the fallback tick.
+	Notify       bool          `yaml:"notify" env:"OUTBOX_NOTIFY" env-default:"true"`
+	PollInterval time.Duration `yaml:"poll_interval" env:"OUTBOX_POLL_INTERVAL" env-default:"2s"`
+	BatchSize    int           `yaml:"batch_size" env:"OUTBOX_BATCH_SIZE" env-default:"10"`
+	// MaxDrainBatches caps how many full batches the poller processes per wake-up.
+	MaxDrainBatches int `yaml:"max_drain_batches" env:"OUTBOX_MAX_DRAIN_BATCHES" env-default:"10"`
+	// MaxInFlight is how many batches the poller publishes at once; the next one is claimed while they are sent.
+	MaxInFlight int `yaml:"max_in_flight" env:"OUTBOX_MAX_IN_FLIGHT" env-default:"1"`
+	// PartitionLease is how long a poller keeps an outbox partition without renewing it.
+	PartitionLease time.Duration `yaml:"partition_lease" env:"OUTBOX_PARTITION_LEASE" env-default:"15s"`
+	// RequireAllAcks makes the relay wait for all in-sync replicas (acks=all)
+	// before marking an event processed. It does not dedupe: consumers' inbox
+	// drops republished copies by event-id.
+	RequireAllAcks bool `yaml:"require_all_acks" env:"OUTBOX_REQUIRE_ALL_ACKS" env-default:"false"`
+	// SlotName and Publication are the logical replication slot and publication used in cdc mode.
+	SlotName    string `yaml:"slot_name" env:"OUTBOX_SLOT_NAME" env-default:"outbox_relay"`
+	Publication string `yaml:"publication" env:"OUTBOX_PUBLICATION" env-default:"outbox_pub"`
+	// StandbyTimeout is how often the cdc relay reports its acknowledged LSN to the server.
+	StandbyTimeout time.Duration `yaml:"standby_timeout" env:"OUTBOX_STANDBY_TIMEOUT" env-default:"10s"`
+}
+
+// Payment configures the payment service authorization policy.
+type Payment struct {
+	MaxAmount float64 `yaml:"max_amount" env:"PAYMENT_MAX_AMOUNT" env-default:"10000"`
+	// CheckBalance enables the per-user ledger balance check.
+	CheckBalance bool `yaml:"check_balance" env:"PAYMENT_CHECK_BALANCE" env-default:"true"`
+	// GatewayDeclineCents makes the fake gateway decline amounts ending in these cents; 0 disables it.
+	GatewayDeclineCents int `yaml:"gateway_decline_cents" env:"PAYMENT_GATEWAY_DECLINE_CENTS" env-default:"13"`
+}
+
+// Ticket configures the fake airline used by the ticket service.
+type Ticket struct {
+	SeatsPerFlight int `yaml:"seats_per_flight" env:"TICKET_SEATS_PER_FLIGHT" env-default:"100"`
+	// RejectingAirlines always refuse bookings, to exercise the compensation path.
+	RejectingAirlines []string `yaml:"rejecting_airlines" env:"TICKET_REJECTING_AIRLINES" env-default:"Chaos Air"`
+}
+
+// Saga configures how the booking saga runs and how its stuck steps are timed out.
+type Saga struct {
+	// BookingMode is the default saga mode of new orders: choreography or orchestration.
+	BookingMode string `yaml:"booking_mode" env:"SAGA_BOOKING_MODE" env-default:"choreography"`
+	// DefinitionFile is an optional YAML file replacing the built-in booking flow.
+	DefinitionFile string `yaml:"definition_file" env:"SAGA_DEFINITION_FILE"`
+	// PaymentDeadline is how long an order may stay CREATED before PaymentTimedOut.
+	PaymentDeadline time.Duration `yaml:"payment_deadline" env:"SAGA_PAYMENT_DEADLINE" env-default:"2m"`
+	// TicketDeadline is how long an order may stay PAYMENT_AUTHORIZED before TicketTimedOut.
+	TicketDeadline time.Duration `yaml:"ticket_deadline" env:"SAGA_TICKET_DEADLINE" env-default:"2m"`
+	ScanInterval   time.Duration `yaml:"scan_interval" env:"SAGA_SCAN_INTERVAL" env-default:"15s"`
+	ScanBatchSize  int           `yaml:"scan_batch_size" env:"SAGA_SCAN_BATCH_SIZE" env-default:"100"`
+	// CommandTimeout is how long the orchestrator waits for a reply before resending a command.
+	CommandTimeout time.Duration `yaml:"command_timeout" env:"SAGA_COMMAND_TIMEOUT" env-default:"30s"`
+	// CommandRetries is how many times a command is resent before the step is given up.
+	CommandRetries int `yaml:"command_retries" env:"SAGA_COMMAND_RETRIES" env-default:"3"`
 }
 
 func New() (*Config, error) {
@@ -0,0 +1,91 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"project/internal/domain/deadletter"
+	domainEvent "project/internal/domain/event"
+	"project/internal/infrastructure/eventcodec"
+	"project/internal/infrastructure/kafka"
+	"project/internal/infrastructure/postgres"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+var deadLettered = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "consumer_dead_letters_total",
+	Help: "The total number of messages moved to the dead-letter queue",
+}, []string{"consumer"})
+
+// DeadLetterHandler moves messages that exhausted their retries to the
+// dead-letter topic and records them in the dead_letters table.
+type DeadLetterHandler struct {
+	producer *kafka.Producer
+	repo     *postgres.DeadLetterRepository
+}
+
+func NewDeadLetterHandler(producer *kafka.Producer, repo *postgres.DeadLetterRepository) *DeadLetterHandler {
+	return &DeadLetterHandler{
+		producer: producer,
+		repo:     repo,
+	}
+}
+
+// Handle publishes msg to the dead-letter topic and persists it. Both steps are
+// attempted even if one fails so that at least one trace of the message survives.
+func (h *DeadLetterHandler) Handle(ctx context.Context, consumerName string, msg kafkago.Message, attempts int, cause error) error {
+	// The service may be shutting down; the message must still be recorded.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+
+	publishErr := h.producer.SendDeadLetter(ctx, msg, consumerName, attempts, cause)
+
+	// Messages without the header are JSON.
+	contentType := headerValue(msg, domainEvent.HeaderContentType)
+	if contentType == "" {
+		contentType = eventcodec.ContentTypeJSON
+	}
+
+	m := &deadletter.Message{
+		ID:          uuid.New().String(),
+		Consumer:    consumerName,
+		Topic:       msg.Topic,
+		Partition:   msg.Partition,
+		Offset:      msg.Offset,
+		Key:         msg.Key,
+		Payload:     msg.Value,
+		ContentType: contentType,
+		Attempts:    attempts,
+		Status:      deadletter.StatusNew,
+		CreatedAt:   time.Now(),
+	}
+	if cause != nil {
+		m.Error = cause.Error()
+	}
+
+	if ev, err := eventcodec.Decode(contentType, msg.Value); err == nil {
+		m.EventID = ev.ID
+		m.EventType = ev.Type
+		m.CorrelationID = ev.CorrelationID
+	} else {
+		// An envelope that does not decode is still findable by its headers.
+		m.EventID = headerValue(msg, domainEvent.HeaderEventID)
+		m.EventType = headerValue(msg, domainEvent.HeaderEventType)
+		m.CorrelationID = headerValue(msg, domainEvent.HeaderCorrelationID)
+	}
+
+	storeErr := h.repo.Create(ctx, m)
+
+	deadLettered.WithLabelValues(consumerName).Inc()
+
+	if publishErr != nil || storeErr != nil {
+		return fmt.Errorf("dead letter: %w", errors.Join(publishErr, storeErr))
+	}
+	return nil
+}
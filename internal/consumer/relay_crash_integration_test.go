@@ -0,0 +1,242 @@
+//go:build integration
+
+package consumer
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"project/internal/domain/outbox"
+	"project/internal/infrastructure/kafka"
+	"project/internal/infrastructure/postgres"
+	"project/internal/worker"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+// isolatedPool returns a pool whose search_path is a fresh schema holding
+// empty copies of the relay and inbox tables, so that the relay under test
+// and the worker of the local stack never see each other's events.
+func isolatedPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	base := testPool(t)
+	ctx := context.Background()
+	schema := "it_" + uuid.New().String()[:8]
+
+	stmts := []string{
+		`CREATE SCHEMA ` + schema,
+		`CREATE TABLE ` + schema + `.outbox (LIKE public.outbox INCLUDING ALL)`,
+		`CREATE TABLE ` + schema + `.outbox_partitions (LIKE public.outbox_partitions INCLUDING ALL)`,
+		`INSERT INTO ` + schema + `.outbox_partitions (partition) SELECT generate_series(0, 63)`,
+		`CREATE TABLE ` + schema + `.outbox_relay_members (LIKE public.outbox_relay_members INCLUDING ALL)`,
+		`CREATE TABLE ` + schema + `.inbox_events (LIKE public.inbox_events INCLUDING ALL)`,
+	}
+	t.Cleanup(func() {
+		base.Exec(context.Background(), `DROP SCHEMA IF EXISTS `+schema+` CASCADE`)
+	})
+	for _, sql := range stmts {
+		if _, err := base.Exec(ctx, sql); err != nil {
+			t.Fatalf("%s: %v", sql, err)
+		}
+	}
+
+	cfg, err := pgxpool.ParseConfig(testDSN())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.ConnConfig.RuntimeParams["search_path"] = schema
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func requireKafka(t *testing.T) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := kafkago.DialContext(ctx, "tcp", testBrokers()[0])
+	if err != nil {
+		t.Skipf("kafka is not available: %v", err)
+	}
+	conn.Close()
+}
+
+// killedBeforeMark is an outbox repository whose relay dies after Kafka
+// acknowledged a batch and before the batch is marked: the marks never reach
+// the database and the relay is stopped, as if its process was killed.
+type killedBeforeMark struct {
+	outbox.Repository
+	kill   context.CancelFunc
+	killed atomic.Bool
+}
+
+var errKilled = errors.New("relay killed before marking the batch")
+
+func (r *killedBeforeMark) MarkProcessed(context.Context, string, []string) (int64, error) {
+	r.killed.Store(true)
+	r.kill()
+	return 0, errKilled
+}
+
+func (r *killedBeforeMark) MarkFailed(context.Context, string, []string) (int64, error) {
+	r.killed.Store(true)
+	r.kill()
+	return 0, errKilled
+}
+
+// runPoller runs an outbox poller until ctx is done and returns a channel
+// closed once it stopped.
+func runPoller(ctx context.Context, pool *pgxpool.Pool, repo outbox.Repository, producer *kafka.Producer, workerID string) <-chan struct{} {
+	poller := worker.NewOutboxPoller(repo, postgres.NewOutboxPartitionRepository(pool), producer, nil, worker.PollerConfig{
+		WorkerID:       workerID,
+		LeaseDuration:  5 * time.Second,
+		ReapInterval:   time.Second,
+		PollInterval:   200 * time.Millisecond,
+		BatchSize:      50,
+		PartitionLease: 3 * time.Second,
+	})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		poller.Run(ctx)
+	}()
+	return stopped
+}
+
+// outboxStatuses returns the status and claimant of every outbox row by id.
+func outboxStatuses(t *testing.T, pool *pgxpool.Pool) map[string][2]string {
+	t.Helper()
+
+	rows, err := pool.Query(context.Background(), `SELECT id::text, status, COALESCE(claimed_by, '') FROM outbox`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	statuses := make(map[string][2]string)
+	for rows.Next() {
+		var id, status, claimedBy string
+		if err := rows.Scan(&id, &status, &claimedBy); err != nil {
+			t.Fatal(err)
+		}
+		statuses[id] = [2]string{status, claimedBy}
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return statuses
+}
+
+// TestRelayKilledBeforeMark kills the outbox relay after Kafka acknowledged a
+// batch and before MarkProcessed, lets a second relay take the stranded
+// events over and republish them, and checks that the consumer handles every
+// outbox event exactly once although Kafka delivers it twice.
+func TestRelayKilledBeforeMark(t *testing.T) {
+	const events = 10
+
+	requireKafka(t)
+	pool := isolatedPool(t)
+	run := uuid.New().String()
+	topic := "integration-relay-crash-" + run
+	name := "integration-relay-crash-" + run
+
+	outboxRepo := postgres.NewOutboxRepository(pool)
+	eventIDs := make([]string, events)
+	for i := range eventIDs {
+		eventIDs[i] = uuid.New().String()
+		err := outboxRepo.Create(context.Background(), &outbox.Event{
+			ID:            eventIDs[i],
+			EventType:     testEventType,
+			Payload:       []byte(`{}`),
+			Status:        "new",
+			CorrelationID: uuid.New().String(),
+			Producer:      "integration-test",
+			CreatedAt:     time.Now(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	producer := kafka.NewProducer(kafka.Config{Brokers: testBrokers(), Topic: topic, RequireAllAcks: true})
+	defer producer.Close()
+
+	// The first relay publishes the batch and dies before marking it.
+	ctx1, kill := context.WithCancel(context.Background())
+	crashing := &killedBeforeMark{Repository: outboxRepo, kill: kill}
+	select {
+	case <-runPoller(ctx1, pool, crashing, producer, "relay-1-"+run):
+	case <-time.After(60 * time.Second):
+		kill()
+		t.Fatal("relay 1 did not publish the batch within 60s")
+	}
+	if !crashing.killed.Load() {
+		t.Fatal("relay 1 stopped without publishing the batch")
+	}
+	for id, s := range outboxStatuses(t, pool) {
+		if s != [2]string{"processing", "relay-1-" + run} {
+			t.Fatalf("event %s is %v after the crash, want still claimed by relay 1", id, s)
+		}
+	}
+
+	// The second relay reclaims the events once relay 1's lease ran out and
+	// publishes them again.
+	ctx2, stop := context.WithCancel(context.Background())
+	stopped := runPoller(ctx2, pool, outboxRepo, producer, "relay-2-"+run)
+	defer func() {
+		stop()
+		<-stopped
+	}()
+
+	deadline := time.Now().Add(60 * time.Second)
+	for {
+		statuses := outboxStatuses(t, pool)
+		processed := 0
+		for _, s := range statuses {
+			if s[0] == "processed" {
+				processed++
+			}
+		}
+		if processed == events {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("relay 2 marked %d of %d events processed within 60s: %v", processed, events, statuses)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	// Kafka holds every event twice; the consumer must handle each once.
+	calls := &handlerCalls{calls: make(map[string]int)}
+	consumeAll(t, pool, name, topic, "group-"+run, calls, 2*events)
+
+	for _, id := range eventIDs {
+		if n := calls.calls[id]; n != 1 {
+			t.Errorf("outbox event %s reached the handler %d times, want 1", id, n)
+		}
+	}
+	if dup := testutil.ToFloat64(eventsHandled.WithLabelValues(name, testEventType, "duplicate")); dup != events {
+		t.Errorf("%v deliveries were dropped as duplicates, want %d", dup, events)
+	}
+
+	var recorded, distinct int
+	err := pool.QueryRow(context.Background(),
+		`SELECT COUNT(*), COUNT(DISTINCT event_id) FROM inbox_events WHERE consumer = $1`, name).Scan(&recorded, &distinct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorded != events || distinct != events {
+		t.Errorf("inbox_events has %d rows for %d events, want one row for each of %d events", recorded, distinct, events)
+	}
+}
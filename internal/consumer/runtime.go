@@ -0,0 +1,342 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	domainEvent "project/internal/domain/event"
+	"project/internal/domain/outbox"
+	"project/internal/infrastructure/eventcodec"
+	"project/internal/infrastructure/kafka"
+	"project/internal/infrastructure/postgres"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+var (
+	eventsHandled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "saga_consumer_events_total",
+		Help: "The total number of events seen by saga consumers, by outcome (processed, duplicate, skipped, dead_lettered)",
+	}, []string{"consumer", "event_type", "outcome"})
+	handleRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "saga_consumer_retries_total",
+		Help: "The total number of retried event handling attempts",
+	}, []string{"consumer", "event_type"})
+	handleDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "saga_consumer_processing_duration_seconds",
+		Help:    "Time taken to handle an event, including the transaction",
+		Buckets: []float64{0.1, 0.5, 1, 2, 5},
+	}, []string{"consumer", "event_type"})
+)
+
+// HandlerFunc handles one event inside the consumer's transaction.
+// ctx carries the transaction, so repositories called with it join it.
+type HandlerFunc func(ctx context.Context, ev *Event) error
+
+// Event is the envelope being handled. Events emitted through it are written
+// to the outbox in the same transaction, chained to the handled event.
+type Event struct {
+	domainEvent.Message
+
+	producer    string
+	emitted     []*outbox.Event
+	afterCommit []func()
+}
+
+// Emit queues an outbox event correlated with the handled event and caused by it.
+func (e *Event) Emit(eventType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal %s payload: %w", eventType, err)
+	}
+
+	e.emitted = append(e.emitted, &outbox.Event{
+		ID:            uuid.New().String(),
+		EventType:     eventType,
+		Payload:       data,
+		Status:        "new",
+		CorrelationID: e.CorrelationID,
+		CausationID:   e.ID,
+		Producer:      e.producer,
+		CreatedAt:     time.Now(),
+	})
+	return nil
+}
+
+// AfterCommit registers fn to run once the transaction has been committed.
+func (e *Event) AfterCommit(fn func()) {
+	e.afterCommit = append(e.afterCommit, fn)
+}
+
+// permanentError marks a failure that retrying cannot fix.
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the runtime dead-letters the message without retrying.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+type Config struct {
+	// Name identifies the consumer in the inbox, outbox producer field, DLQ and metrics.
+	Name        string
+	MaxRetries  int
+	Pool        *pgxpool.Pool
+	Kafka       *kafka.Consumer
+	Inbox       *postgres.InboxRepository
+	Outbox      *postgres.OutboxRepository
+	DeadLetters *DeadLetterHandler
+	Logger      *slog.Logger
+}
+
+// Runtime runs the fetch → inbox dedupe → handler → outbox → commit loop
+// shared by all saga participants.
+type Runtime struct {
+	cfg      Config
+	handlers map[string]HandlerFunc
+}
+
+func NewRuntime(cfg Config) *Runtime {
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = 5
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	return &Runtime{
+		cfg:      cfg,
+		handlers: make(map[string]HandlerFunc),
+	}
+}
+
+// Handle registers fn for eventType. Events of other types are committed and skipped.
+func (r *Runtime) Handle(eventType string, fn HandlerFunc) {
+	r.handlers[eventType] = fn
+}
+
+// HandleTyped registers fn for eventType, decoding the payload into T first.
+// A payload that cannot be decoded is dead-lettered without retries.
+func HandleTyped[T any](r *Runtime, eventType string, fn func(ctx context.Context, ev *Event, payload T) error) {
+	r.Handle(eventType, func(ctx context.Context, ev *Event) error {
+		var payload T
+		if err := json.Unmarshal(ev.Payload, &payload); err != nil {
+			return Permanent(fmt.Errorf("unmarshal %s payload: %w", eventType, err))
+		}
+		return fn(ctx, ev, payload)
+	})
+}
+
+// Run consumes messages until ctx is cancelled. A message already being handled
+// is finished before Run returns; pending retries are abandoned and the message
+// is redelivered after restart because its offset is not committed.
+func (r *Runtime) Run(ctx context.Context) error {
+	logger := r.cfg.Logger
+
+	eventTypes := make([]string, 0, len(r.handlers))
+	for eventType := range r.handlers {
+		eventTypes = append(eventTypes, eventType)
+	}
+	topics := r.cfg.Kafka.Subscribe(eventTypes)
+	logger.Info("Consumer subscribed", "consumer", r.cfg.Name, "topics", topics)
+	if len(topics) > 1 {
+		logger.Warn("Consumer reads several topics; events of one order on different topics are not ordered",
+			"consumer", r.cfg.Name, "topics", topics)
+	}
+
+	for {
+		msg, err := r.cfg.Kafka.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				logger.Info("Consumer stopping", "consumer", r.cfg.Name)
+				return nil
+			}
+			logger.Error("failed to fetch message", "error", err)
+			if !sleepCtx(ctx, time.Second) {
+				return nil
+			}
+			continue
+		}
+
+		if !r.handleMessage(ctx, msg) {
+			logger.Info("Consumer stopping before message was handled", "consumer", r.cfg.Name, "offset", msg.Offset)
+			return nil
+		}
+	}
+}
+
+// handleMessage returns false if shutdown interrupted the retries.
+func (r *Runtime) handleMessage(ctx context.Context, msg kafkago.Message) bool {
+	logger := r.cfg.Logger
+	// Work on a message is not interrupted by shutdown; only retry waits are.
+	workCtx := context.WithoutCancel(ctx)
+
+	// Most services handle a few of the event types on the topic; skip the
+	// rest by header before decoding the body.
+	eventType := headerValue(msg, domainEvent.HeaderEventType)
+	if eventType != "" {
+		if _, ok := r.handlers[eventType]; !ok {
+			eventsHandled.WithLabelValues(r.cfg.Name, eventType, "skipped").Inc()
+			r.commit(workCtx, msg)
+			return true
+		}
+	}
+
+	ev, err := eventcodec.Decode(headerValue(msg, domainEvent.HeaderContentType), msg.Value)
+	if err != nil {
+		if eventType != "" {
+			// The header names an event this consumer handles: keep the
+			// corrupt message for the operator instead of dropping it.
+			r.deadLetter(workCtx, msg, eventType, headerValue(msg, domainEvent.HeaderEventID), 1, Permanent(fmt.Errorf("decode %s envelope: %w", eventType, err)))
+			return true
+		}
+		// Not our envelope (or corrupt). Commit and move on.
+		logger.Error("failed to unmarshal event envelope", "error", err)
+		r.commit(workCtx, msg)
+		return true
+	}
+	// The inbox dedupes on the outbox id; messages published before the
+	// relay set the header carry it only in the envelope.
+	if id := headerValue(msg, domainEvent.HeaderEventID); id != "" {
+		ev.ID = id
+	}
+
+	handler, ok := r.handlers[ev.Type]
+	if !ok {
+		eventsHandled.WithLabelValues(r.cfg.Name, ev.Type, "skipped").Inc()
+		r.commit(workCtx, msg)
+		return true
+	}
+
+	for attempt := 0; attempt <= r.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			backoff := time.Duration(1<<attempt) * time.Second
+			logger.Info("Retry attempt", "attempt", attempt, "max", r.cfg.MaxRetries, "backoff", backoff, "event_id", ev.ID)
+			handleRetries.WithLabelValues(r.cfg.Name, ev.Type).Inc()
+			if !sleepCtx(ctx, backoff) {
+				return false
+			}
+		}
+
+		started := time.Now()
+		processErr := r.process(workCtx, ev, handler)
+		handleDuration.WithLabelValues(r.cfg.Name, ev.Type).Observe(time.Since(started).Seconds())
+
+		if processErr == nil {
+			r.commit(workCtx, msg)
+			return true
+		}
+
+		logger.Error("Processing failed", "type", ev.Type, "event_id", ev.ID, "error", processErr)
+
+		var permanent *permanentError
+		if attempt == r.cfg.MaxRetries || errors.As(processErr, &permanent) {
+			r.deadLetter(workCtx, msg, ev.Type, ev.ID, attempt+1, processErr)
+			return true
+		}
+	}
+
+	return true
+}
+
+// deadLetter hands msg to the dead-letter handler and commits it.
+func (r *Runtime) deadLetter(ctx context.Context, msg kafkago.Message, eventType, eventID string, attempts int, cause error) {
+	r.cfg.Logger.Error("DLQ: Moving message to dead-letter topic", "attempts", attempts, "type", eventType, "event_id", eventID, "error", cause)
+	if r.cfg.DeadLetters != nil {
+		if err := r.cfg.DeadLetters.Handle(ctx, r.cfg.Name, msg, attempts, cause); err != nil {
+			r.cfg.Logger.Error("failed to dead-letter message", "error", err)
+		}
+	}
+	eventsHandled.WithLabelValues(r.cfg.Name, eventType, "dead_lettered").Inc()
+	r.commit(ctx, msg)
+}
+
+func (r *Runtime) process(ctx context.Context, msg domainEvent.Message, handler HandlerFunc) error {
+	// Handlers only know the current payload version.
+	msg, err := domainEvent.Upcast(msg)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	r.cfg.Logger.Info("Received event", "type", msg.Type, "correlation_id", msg.CorrelationID, "event_id", msg.ID)
+
+	tx, err := r.cfg.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	isNew, err := r.cfg.Inbox.SaveIfNotExists(ctx, tx, r.cfg.Name, msg.ID, msg.Type, msg.CorrelationID)
+	if err != nil {
+		return fmt.Errorf("inbox save: %w", err)
+	}
+
+	if !isNew {
+		if err := tx.Commit(ctx); err != nil {
+			return fmt.Errorf("commit noop tx: %w", err)
+		}
+		eventsHandled.WithLabelValues(r.cfg.Name, msg.Type, "duplicate").Inc()
+		return nil
+	}
+
+	ev := &Event{Message: msg, producer: r.cfg.Name}
+	txCtx := postgres.WithTx(ctx, tx)
+
+	if err := handler(txCtx, ev); err != nil {
+		return err
+	}
+
+	for _, e := range ev.emitted {
+		if err := r.cfg.Outbox.Create(txCtx, e); err != nil {
+			return fmt.Errorf("create outbox event: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	eventsHandled.WithLabelValues(r.cfg.Name, msg.Type, "processed").Inc()
+	for _, fn := range ev.afterCommit {
+		fn()
+	}
+	return nil
+}
+
+func (r *Runtime) commit(ctx context.Context, msg kafkago.Message) {
+	if err := r.cfg.Kafka.CommitMessages(ctx, msg); err != nil {
+		r.cfg.Logger.Error("failed to commit kafka message", "error", err)
+	}
+}
+
+func headerValue(msg kafkago.Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// sleepCtx waits for d and reports false if ctx was cancelled first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
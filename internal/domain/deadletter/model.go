@@ -0,0 +1,50 @@
+package deadletter
+
+import (
+	"errors"
+	"time"
+)
+
+// Message is a Kafka message a consumer gave up on after exhausting retries.
+// EventID, EventType and CorrelationID are filled when the envelope could be parsed.
+// ContentType is the codec of Payload (see eventcodec).
+type Message struct {
+	ID            string    `json:"id"`
+	Consumer      string    `json:"consumer"`
+	Topic         string    `json:"topic"`
+	Partition     int       `json:"partition"`
+	Offset        int64     `json:"offset"`
+	Key           []byte    `json:"key,omitempty"`
+	Payload       []byte    `json:"payload"`
+	ContentType   string    `json:"content_type"`
+	EventID       string    `json:"event_id,omitempty"`
+	EventType     string    `json:"event_type,omitempty"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	Error         string    `json:"error"`
+	Attempts      int       `json:"attempts"`
+	Status        string    `json:"status"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+const (
+	StatusNew       = "new"
+	StatusReplayed  = "replayed"
+	StatusDiscarded = "discarded"
+)
+
+var (
+	ErrNotFound  = errors.New("dead letter not found")
+	ErrDiscarded = errors.New("dead letter is discarded")
+)
+
+// Filter narrows down dead letters for listing. Zero values mean "any".
+type Filter struct {
+	Consumer      string
+	EventType     string
+	CorrelationID string
+	Status        string
+	From          time.Time
+	To            time.Time
+	Limit         int
+}
@@ -0,0 +1,70 @@
+package event
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Schema is the current payload layout of an event type.
+type Schema struct {
+	Type    string
+	Version int
+	// Payload is the Go type the payload is encoded from and decoded into.
+	Payload reflect.Type
+}
+
+// catalog lists every event type of the saga with its current payload version.
+// A change that breaks existing consumers (a field removed, renamed or retyped)
+// needs a new version and an upcaster from the previous one (see upcast.go);
+// catalog_test.go fails otherwise.
+var catalog = map[string]Schema{}
+
+func init() {
+	register[OrderCreated](TypeOrderCreated, 2)
+	register[OrderCreated](TypeAuthorizePayment, 2)
+	register[PaymentAuthorized](TypePaymentAuthorized, 1)
+	register[PaymentFailed](TypePaymentFailed, 1)
+	register[SagaTimedOut](TypePaymentTimedOut, 1)
+	register[Compensation](TypeVoidPayment, 1)
+	register[PaymentVoided](TypePaymentVoided, 1)
+	register[PaymentAuthorized](TypeIssueTicket, 1)
+	register[TicketIssued](TypeTicketIssued, 1)
+	register[TicketFailed](TypeTicketFailed, 1)
+	register[SagaTimedOut](TypeTicketTimedOut, 1)
+	register[RefundInitiated](TypeRefundInitiated, 1)
+	register[PaymentRefunded](TypePaymentRefunded, 1)
+	register[TicketCancelled](TypeTicketCancelled, 1)
+}
+
+func register[T any](eventType string, version int) {
+	catalog[eventType] = Schema{
+		Type:    eventType,
+		Version: version,
+		Payload: reflect.TypeFor[T](),
+	}
+}
+
+// Lookup returns the current schema of eventType.
+func Lookup(eventType string) (Schema, bool) {
+	s, ok := catalog[eventType]
+	return s, ok
+}
+
+// Version returns the current payload version of eventType, 1 for types
+// outside the catalog.
+func Version(eventType string) int {
+	if s, ok := catalog[eventType]; ok {
+		return s.Version
+	}
+	return 1
+}
+
+// Types returns the event types of the catalog, sorted.
+func Types() []string {
+	types := make([]string, 0, len(catalog))
+	for t := range catalog {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
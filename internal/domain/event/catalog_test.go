@@ -0,0 +1,129 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// schemasDir holds the committed JSON Schemas of the catalog, written by
+// `make schemas`.
+var schemasDir = filepath.Join("..", "..", "..", "schemas", "events")
+
+// readCommitted returns the committed schema of s, or nil if there is none.
+func readCommitted(t *testing.T, s Schema) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(schemasDir, s.FileName()))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// TestCatalogCompatible fails when a payload changed in a way that breaks the
+// consumers of its published version: bump the version in the catalog and
+// register an upcaster instead.
+func TestCatalogCompatible(t *testing.T) {
+	for _, eventType := range Types() {
+		s, _ := Lookup(eventType)
+		data := readCommitted(t, s)
+		if data == nil {
+			continue
+		}
+
+		var published JSONSchema
+		if err := json.Unmarshal(data, &published); err != nil {
+			t.Fatalf("%s: %v", s.FileName(), err)
+		}
+		if changes := BreakingChanges(&published, s.JSONSchema()); len(changes) > 0 {
+			t.Errorf("%s: breaking change, bump the %s version in the event catalog:", s.FileName(), s.Type)
+			for _, c := range changes {
+				t.Errorf("  %s", c)
+			}
+		}
+	}
+}
+
+func TestCatalogSchemasCommitted(t *testing.T) {
+	for _, eventType := range Types() {
+		s, _ := Lookup(eventType)
+		want, err := s.MarshalJSONSchema()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := readCommitted(t, s)
+		switch {
+		case got == nil:
+			t.Errorf("%s: not committed, run `make schemas`", s.FileName())
+		case !bytes.Equal(got, want):
+			t.Errorf("%s: out of date, run `make schemas`", s.FileName())
+		}
+	}
+}
+
+func TestCatalogUpcasters(t *testing.T) {
+	for _, eventType := range Types() {
+		s, _ := Lookup(eventType)
+		for v := 1; v < s.Version; v++ {
+			if !HasUpcaster(eventType, v) {
+				t.Errorf("%s: no upcaster from v%d", eventType, v)
+			}
+		}
+	}
+}
+
+func TestBreakingChanges(t *testing.T) {
+	type published struct {
+		OrderID string  `json:"order_id"`
+		Amount  float64 `json:"amount"`
+		Reason  string  `json:"reason"`
+	}
+
+	tests := []struct {
+		name    string
+		current any
+		want    []string
+	}{
+		{"unchanged", published{}, nil},
+		{"field added", struct {
+			OrderID string  `json:"order_id"`
+			Amount  float64 `json:"amount"`
+			Reason  string  `json:"reason"`
+			Seat    string  `json:"seat"`
+		}{}, nil},
+		{"field removed", struct {
+			OrderID string  `json:"order_id"`
+			Amount  float64 `json:"amount"`
+		}{}, []string{"payload.reason: removed"}},
+		{"field retyped", struct {
+			OrderID string `json:"order_id"`
+			Amount  string `json:"amount"`
+			Reason  string `json:"reason"`
+		}{}, []string{"payload.amount: type changed from number to string"}},
+		{"field made optional", struct {
+			OrderID string  `json:"order_id"`
+			Amount  float64 `json:"amount"`
+			Reason  string  `json:"reason,omitempty"`
+		}{}, []string{"payload.reason: no longer required"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := schemaOf(reflect.TypeOf(published{}))
+			got := BreakingChanges(old, schemaOf(reflect.TypeOf(tt.current)))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("BreakingChanges = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
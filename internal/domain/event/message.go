@@ -2,17 +2,84 @@ package event
 
 import (
 	"encoding/json"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// Kafka headers set by the outbox relay, so consumers can route and filter
+// messages without decoding the body.
+const (
+	// HeaderEventID carries the outbox id of the event. The relay may publish an
+	// event more than once (crash between send and MarkProcessed, writer retries);
+	// every copy has the same id, and consumers dedupe on it through the inbox.
+	HeaderEventID       = "event-id"
+	HeaderEventType     = "event-type"
+	HeaderCorrelationID = "correlation-id"
+	HeaderCausationID   = "causation-id"
+	HeaderProducer      = "producer"
+	// HeaderSchemaVersion is the payload schema version, see Message.Version.
+	HeaderSchemaVersion = "schema-version"
+	// HeaderTraceParent is a W3C trace context derived from the saga, see TraceParent.
+	HeaderTraceParent = "traceparent"
+	// HeaderContentType names the codec of the message value; absent means JSON.
+	HeaderContentType = "content-type"
+)
+
 // Message is the envelope published to Kafka.
-// Payload is kept as raw JSON produced by the originating service.
+// Payload is kept as raw JSON produced by the originating service; its layout
+// is the catalog schema of Type at SchemaVersion.
 type Message struct {
-	ID            string          `json:"id"`
-	Type          string          `json:"type"`
-	CorrelationID string          `json:"correlation_id"`
-	CausationID   string          `json:"causation_id,omitempty"`
-	Producer      string          `json:"producer"`
+	ID            string `json:"id"`
+	Type          string `json:"type"`
+	CorrelationID string `json:"correlation_id"`
+	CausationID   string `json:"causation_id,omitempty"`
+	Producer      string `json:"producer"`
+	// SchemaVersion is 0 in messages published before payloads were versioned,
+	// which means version 1.
+	SchemaVersion int             `json:"schema_version,omitempty"`
 	OccurredAt    time.Time       `json:"occurred_at"`
 	Payload       json.RawMessage `json:"payload"`
 }
+
+// Version returns the payload schema version of m.
+func (m Message) Version() int {
+	if m.SchemaVersion < 1 {
+		return 1
+	}
+	return m.SchemaVersion
+}
+
+// Headers returns the Kafka headers describing m. Empty values are omitted.
+func (m Message) Headers() map[string]string {
+	headers := map[string]string{
+		HeaderEventID:       m.ID,
+		HeaderEventType:     m.Type,
+		HeaderSchemaVersion: strconv.Itoa(m.Version()),
+	}
+	if m.CorrelationID != "" {
+		headers[HeaderCorrelationID] = m.CorrelationID
+	}
+	if m.CausationID != "" {
+		headers[HeaderCausationID] = m.CausationID
+	}
+	if m.Producer != "" {
+		headers[HeaderProducer] = m.Producer
+	}
+	if tp := TraceParent(m.CorrelationID, m.ID); tp != "" {
+		headers[HeaderTraceParent] = tp
+	}
+	return headers
+}
+
+// TraceParent builds a W3C traceparent in which the whole saga is one trace
+// (trace id = correlation id) and each event is a span (span id = first half
+// of the event id). It returns "" if either id is not a UUID.
+func TraceParent(correlationID, eventID string) string {
+	traceID := strings.ReplaceAll(correlationID, "-", "")
+	spanID := strings.ReplaceAll(eventID, "-", "")
+	if len(traceID) != 32 || len(spanID) != 32 {
+		return ""
+	}
+	return "00-" + strings.ToLower(traceID) + "-" + strings.ToLower(spanID[:16]) + "-01"
+}
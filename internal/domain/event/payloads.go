@@ -0,0 +1,122 @@
+package event
+
+import "time"
+
+// Event types of the booking saga. Commands (AuthorizePayment, IssueTicket,
+// VoidPayment) are sent by the orchestrator; the rest are facts published by
+// the service that owns them.
+const (
+	TypeOrderCreated      = "OrderCreated"
+	TypeAuthorizePayment  = "AuthorizePayment"
+	TypePaymentAuthorized = "PaymentAuthorized"
+	TypePaymentFailed     = "PaymentFailed"
+	TypePaymentTimedOut   = "PaymentTimedOut"
+	TypeVoidPayment       = "VoidPayment"
+	TypePaymentVoided     = "PaymentVoided"
+	TypeIssueTicket       = "IssueTicket"
+	TypeTicketIssued      = "TicketIssued"
+	TypeTicketFailed      = "TicketFailed"
+	TypeTicketTimedOut    = "TicketTimedOut"
+	TypeRefundInitiated   = "RefundInitiated"
+	TypePaymentRefunded   = "PaymentRefunded"
+	TypeTicketCancelled   = "TicketCancelled"
+)
+
+// OrderCreated is published by the order service when a booking is placed.
+// AuthorizePayment carries the same payload. Version 2 renamed id to order_id
+// like in every other payload.
+type OrderCreated struct {
+	OrderID     string    `json:"order_id"`
+	UserID      string    `json:"user_id"`
+	Status      string    `json:"status"`
+	TotalAmount float64   `json:"total_amount"`
+	FromCity    string    `json:"from_city"`
+	ToCity      string    `json:"to_city"`
+	TravelDate  string    `json:"travel_date"` // YYYY-MM-DD
+	TravelTime  string    `json:"travel_time"` // HH:MM
+	Airline     string    `json:"airline"`
+	SagaMode    string    `json:"saga_mode"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// PaymentAuthorized carries the flight details on to the ticket service.
+// IssueTicket carries the same payload.
+type PaymentAuthorized struct {
+	OrderID    string  `json:"order_id"`
+	PaymentID  string  `json:"payment_id"`
+	Amount     float64 `json:"amount"`
+	FromCity   string  `json:"from_city"`
+	ToCity     string  `json:"to_city"`
+	TravelDate string  `json:"travel_date"`
+	TravelTime string  `json:"travel_time"`
+	Airline    string  `json:"airline"`
+	SagaMode   string  `json:"saga_mode"`
+}
+
+type PaymentFailed struct {
+	OrderID    string  `json:"order_id"`
+	PaymentID  string  `json:"payment_id"`
+	Amount     float64 `json:"amount"`
+	ReasonCode string  `json:"reason_code"`
+	Reason     string  `json:"reason"`
+}
+
+// SagaTimedOut is the payload of PaymentTimedOut and TicketTimedOut, emitted
+// by the watchdog (choreography) or the orchestrator.
+type SagaTimedOut struct {
+	OrderID    string    `json:"order_id"`
+	Status     string    `json:"status"`
+	StuckSince time.Time `json:"stuck_since,omitzero"`
+	Deadline   string    `json:"deadline"`
+	SagaMode   string    `json:"saga_mode"`
+}
+
+// Compensation is the payload of compensation commands (VoidPayment).
+type Compensation struct {
+	OrderID  string `json:"order_id"`
+	Reason   string `json:"reason"`
+	SagaMode string `json:"saga_mode"`
+}
+
+// PaymentVoided has empty payment fields when there was nothing to void.
+type PaymentVoided struct {
+	OrderID   string  `json:"order_id"`
+	PaymentID string  `json:"payment_id"`
+	Amount    float64 `json:"amount"`
+	Reason    string  `json:"reason"`
+}
+
+type TicketIssued struct {
+	OrderID  string `json:"order_id"`
+	TicketID string `json:"ticket_id"`
+}
+
+type TicketFailed struct {
+	OrderID    string `json:"order_id"`
+	TicketID   string `json:"ticket_id"`
+	PaymentID  string `json:"payment_id"`
+	ReasonCode string `json:"reason_code"`
+	Reason     string `json:"reason"`
+	SagaMode   string `json:"saga_mode"`
+}
+
+type RefundInitiated struct {
+	OrderID   string    `json:"order_id"`
+	Reason    string    `json:"reason"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// PaymentRefunded has no payment fields when there was nothing to refund.
+type PaymentRefunded struct {
+	OrderID   string  `json:"order_id"`
+	PaymentID string  `json:"payment_id,omitempty"`
+	RefundID  string  `json:"refund_id,omitempty"`
+	Amount    float64 `json:"amount"`
+}
+
+// TicketCancelled has no ticket id when no ticket was issued.
+type TicketCancelled struct {
+	OrderID  string `json:"order_id"`
+	TicketID string `json:"ticket_id,omitempty"`
+}
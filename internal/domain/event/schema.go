@@ -0,0 +1,162 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is the subset of JSON Schema generated for payload types.
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	ID                   string                 `json:"$id,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	ContentEncoding      string                 `json:"contentEncoding,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+}
+
+// FileName is the name the schema of s is committed under, e.g. OrderCreated.v1.json.
+func (s Schema) FileName() string {
+	return fmt.Sprintf("%s.v%d.json", s.Type, s.Version)
+}
+
+// JSONSchema describes the payload of s. Fields without omitempty/omitzero
+// are required.
+func (s Schema) JSONSchema() *JSONSchema {
+	js := schemaOf(s.Payload)
+	js.Schema = jsonSchemaDraft
+	js.ID = s.FileName()
+	js.Title = s.Type
+	return js
+}
+
+// MarshalJSONSchema renders the schema of s the way it is committed.
+func (s Schema) MarshalJSONSchema() ([]byte, error) {
+	data, err := json.MarshalIndent(s.JSONSchema(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s schema: %w", s.Type, err)
+	}
+	return append(data, '\n'), nil
+}
+
+var (
+	timeType       = reflect.TypeFor[time.Time]()
+	rawMessageType = reflect.TypeFor[json.RawMessage]()
+)
+
+func schemaOf(t reflect.Type) *JSONSchema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &JSONSchema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		// Any JSON value.
+		return &JSONSchema{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &JSONSchema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &JSONSchema{Type: "array", Items: schemaOf(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: schemaOf(t.Elem())}
+	case reflect.Struct:
+		js := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" && opts == "" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			js.Properties[name] = schemaOf(f.Type)
+			if !strings.Contains(opts, "omitempty") && !strings.Contains(opts, "omitzero") {
+				js.Required = append(js.Required, name)
+			}
+		}
+		slices.Sort(js.Required)
+		return js
+	}
+	return &JSONSchema{}
+}
+
+// BreakingChanges lists the differences between a published schema and its
+// replacement that would break consumers still decoding the published one:
+// a property removed, retyped or made optional. Added properties are fine.
+func BreakingChanges(published, current *JSONSchema) []string {
+	var changes []string
+	compareSchema("payload", published, current, &changes)
+	slices.Sort(changes)
+	return changes
+}
+
+func compareSchema(path string, old, cur *JSONSchema, changes *[]string) {
+	if old.Type != cur.Type || old.Format != cur.Format || old.ContentEncoding != cur.ContentEncoding {
+		*changes = append(*changes, fmt.Sprintf("%s: type changed from %s to %s", path, describe(old), describe(cur)))
+		return
+	}
+
+	for name, oldProp := range old.Properties {
+		curProp, ok := cur.Properties[name]
+		if !ok {
+			*changes = append(*changes, fmt.Sprintf("%s.%s: removed", path, name))
+			continue
+		}
+		compareSchema(path+"."+name, oldProp, curProp, changes)
+	}
+	for _, name := range old.Required {
+		if _, ok := cur.Properties[name]; ok && !slices.Contains(cur.Required, name) {
+			*changes = append(*changes, fmt.Sprintf("%s.%s: no longer required", path, name))
+		}
+	}
+
+	if old.Items != nil && cur.Items != nil {
+		compareSchema(path+"[]", old.Items, cur.Items, changes)
+	}
+	if old.AdditionalProperties != nil && cur.AdditionalProperties != nil {
+		compareSchema(path+"{}", old.AdditionalProperties, cur.AdditionalProperties, changes)
+	}
+}
+
+func describe(s *JSONSchema) string {
+	d := s.Type
+	if d == "" {
+		d = "any"
+	}
+	if s.Format != "" {
+		d += "(" + s.Format + ")"
+	}
+	if s.ContentEncoding != "" {
+		d += "(" + s.ContentEncoding + ")"
+	}
+	return d
+}
@@ -0,0 +1,82 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownVersion is returned for payloads newer than the catalog of this
+// build, e.g. from a producer deployed before its consumers.
+var ErrUnknownVersion = errors.New("unknown event version")
+
+// UpcastFunc rewrites a payload of one version into the next one.
+type UpcastFunc func(payload json.RawMessage) (json.RawMessage, error)
+
+type upcastKey struct {
+	eventType string
+	from      int
+}
+
+var upcasters = map[upcastKey]UpcastFunc{}
+
+func init() {
+	RegisterUpcaster(TypeOrderCreated, 1, orderCreatedV1ToV2)
+	RegisterUpcaster(TypeAuthorizePayment, 1, orderCreatedV1ToV2)
+}
+
+// RegisterUpcaster registers fn to turn version from of eventType into from+1.
+func RegisterUpcaster(eventType string, from int, fn UpcastFunc) {
+	upcasters[upcastKey{eventType, from}] = fn
+}
+
+// HasUpcaster reports whether version from of eventType can be upcast.
+func HasUpcaster(eventType string, from int) bool {
+	_, ok := upcasters[upcastKey{eventType, from}]
+	return ok
+}
+
+// Upcast brings the payload of m to the catalog version of its type one
+// version at a time, so messages written by older producers (still in Kafka,
+// the DLQ or a replay) decode into the current payload struct. Types outside
+// the catalog are returned unchanged.
+func Upcast(m Message) (Message, error) {
+	s, ok := Lookup(m.Type)
+	if !ok {
+		return m, nil
+	}
+
+	version := m.Version()
+	if version > s.Version {
+		return m, fmt.Errorf("%s v%d (latest known v%d): %w", m.Type, version, s.Version, ErrUnknownVersion)
+	}
+
+	for ; version < s.Version; version++ {
+		fn, ok := upcasters[upcastKey{m.Type, version}]
+		if !ok {
+			return m, fmt.Errorf("no upcaster for %s v%d", m.Type, version)
+		}
+		payload, err := fn(m.Payload)
+		if err != nil {
+			return m, fmt.Errorf("upcast %s v%d: %w", m.Type, version, err)
+		}
+		m.Payload = payload
+	}
+	m.SchemaVersion = version
+	return m, nil
+}
+
+// orderCreatedV1ToV2 renames id to order_id.
+func orderCreatedV1ToV2(payload json.RawMessage) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, err
+	}
+	if id, ok := fields["id"]; ok {
+		if _, ok := fields["order_id"]; !ok {
+			fields["order_id"] = id
+		}
+		delete(fields, "id")
+	}
+	return json.Marshal(fields)
+}
@@ -0,0 +1,162 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestUpcastOrderCreatedV1(t *testing.T) {
+	for _, eventType := range []string{TypeOrderCreated, TypeAuthorizePayment} {
+		t.Run(eventType, func(t *testing.T) {
+			m := Message{
+				Type:          eventType,
+				SchemaVersion: 1,
+				Payload:       json.RawMessage(`{"id":"o-1","user_id":"u-1","total_amount":100}`),
+			}
+
+			got, err := Upcast(m)
+			if err != nil {
+				t.Fatalf("Upcast: %v", err)
+			}
+			if got.SchemaVersion != 2 {
+				t.Errorf("SchemaVersion = %d, want 2", got.SchemaVersion)
+			}
+
+			var fields map[string]any
+			if err := json.Unmarshal(got.Payload, &fields); err != nil {
+				t.Fatalf("unmarshal payload: %v", err)
+			}
+			if _, ok := fields["id"]; ok {
+				t.Errorf("payload still has id: %s", got.Payload)
+			}
+
+			var p OrderCreated
+			if err := json.Unmarshal(got.Payload, &p); err != nil {
+				t.Fatalf("unmarshal OrderCreated: %v", err)
+			}
+			if p.OrderID != "o-1" || p.UserID != "u-1" || p.TotalAmount != 100 {
+				t.Errorf("payload = %+v, want order_id o-1, user_id u-1, total_amount 100", p)
+			}
+		})
+	}
+}
+
+// Messages from before payloads were versioned have no schema_version.
+func TestUpcastUnversionedIsV1(t *testing.T) {
+	got, err := Upcast(Message{Type: TypeOrderCreated, Payload: json.RawMessage(`{"id":"o-1"}`)})
+	if err != nil {
+		t.Fatalf("Upcast: %v", err)
+	}
+
+	var p OrderCreated
+	if err := json.Unmarshal(got.Payload, &p); err != nil {
+		t.Fatalf("unmarshal OrderCreated: %v", err)
+	}
+	if p.OrderID != "o-1" || got.SchemaVersion != 2 {
+		t.Errorf("got v%d %s, want v2 with order_id o-1", got.SchemaVersion, got.Payload)
+	}
+}
+
+func TestUpcastCurrentVersionUnchanged(t *testing.T) {
+	payload := json.RawMessage(`{"order_id":"o-1","id":"kept"}`)
+	m := Message{Type: TypeOrderCreated, SchemaVersion: 2, Payload: payload}
+
+	got, err := Upcast(m)
+	if err != nil {
+		t.Fatalf("Upcast: %v", err)
+	}
+	if string(got.Payload) != string(payload) || got.SchemaVersion != 2 {
+		t.Errorf("got v%d %s, want v2 %s", got.SchemaVersion, got.Payload, payload)
+	}
+}
+
+func TestUpcastUnknownVersion(t *testing.T) {
+	m := Message{Type: TypeOrderCreated, SchemaVersion: 3, Payload: json.RawMessage(`{}`)}
+
+	if _, err := Upcast(m); !errors.Is(err, ErrUnknownVersion) {
+		t.Fatalf("Upcast v3: err = %v, want ErrUnknownVersion", err)
+	}
+}
+
+func TestUpcastTypeOutsideCatalog(t *testing.T) {
+	m := Message{Type: "NotInCatalog", Payload: json.RawMessage(`{"id":"x"}`)}
+
+	got, err := Upcast(m)
+	if err != nil {
+		t.Fatalf("Upcast: %v", err)
+	}
+	if string(got.Payload) != `{"id":"x"}` {
+		t.Errorf("payload = %s, want it unchanged", got.Payload)
+	}
+}
+
+func TestUpcastChain(t *testing.T) {
+	const eventType = "TestChained"
+	withSchema[struct{}](t, eventType, 3)
+
+	appendStep := func(step string) UpcastFunc {
+		return func(payload json.RawMessage) (json.RawMessage, error) {
+			var p struct {
+				Steps []string `json:"steps"`
+			}
+			if err := json.Unmarshal(payload, &p); err != nil {
+				return nil, err
+			}
+			p.Steps = append(p.Steps, step)
+			return json.Marshal(p)
+		}
+	}
+	RegisterUpcaster(eventType, 1, appendStep("v1to2"))
+	RegisterUpcaster(eventType, 2, appendStep("v2to3"))
+
+	tests := []struct {
+		version int
+		want    string
+	}{
+		{1, `{"steps":["v1to2","v2to3"]}`},
+		{2, `{"steps":["v2to3"]}`},
+		{3, `{"steps":[]}`},
+	}
+	for _, tt := range tests {
+		got, err := Upcast(Message{Type: eventType, SchemaVersion: tt.version, Payload: json.RawMessage(`{"steps":[]}`)})
+		if err != nil {
+			t.Fatalf("Upcast v%d: %v", tt.version, err)
+		}
+		if string(got.Payload) != tt.want || got.SchemaVersion != 3 {
+			t.Errorf("Upcast v%d = v%d %s, want v3 %s", tt.version, got.SchemaVersion, got.Payload, tt.want)
+		}
+	}
+}
+
+func TestUpcastMissingStep(t *testing.T) {
+	const eventType = "TestMissingStep"
+	withSchema[struct{}](t, eventType, 3)
+	RegisterUpcaster(eventType, 1, func(p json.RawMessage) (json.RawMessage, error) { return p, nil })
+
+	if _, err := Upcast(Message{Type: eventType, SchemaVersion: 1, Payload: json.RawMessage(`{}`)}); err == nil {
+		t.Fatal("Upcast without a v2 upcaster succeeded")
+	}
+}
+
+func TestUpcasterFailure(t *testing.T) {
+	m := Message{Type: TypeOrderCreated, SchemaVersion: 1, Payload: json.RawMessage(`not json`)}
+
+	if _, err := Upcast(m); err == nil {
+		t.Fatal("Upcast of a malformed v1 payload succeeded")
+	}
+}
+
+// withSchema adds eventType to the catalog for the duration of the test.
+func withSchema[T any](t *testing.T, eventType string, version int) {
+	t.Helper()
+	register[T](eventType, version)
+	t.Cleanup(func() {
+		delete(catalog, eventType)
+		for k := range upcasters {
+			if k.eventType == eventType {
+				delete(upcasters, k)
+			}
+		}
+	})
+}
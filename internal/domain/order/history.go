@@ -0,0 +1,23 @@
+package order
+
+import "time"
+
+// StatusChange describes a requested status change and what caused it.
+type StatusChange struct {
+	To          string
+	ChangedBy   string
+	EventType   string
+	CausationID string
+}
+
+// HistoryEntry is one recorded status change of an order.
+type HistoryEntry struct {
+	ID          int64     `json:"id"`
+	OrderID     string    `json:"order_id"`
+	FromStatus  string    `json:"from_status,omitempty"`
+	ToStatus    string    `json:"to_status"`
+	ChangedBy   string    `json:"changed_by"`
+	EventType   string    `json:"event_type,omitempty"`
+	CausationID string    `json:"causation_id,omitempty"`
+	ChangedAt   time.Time `json:"changed_at"`
+}
@@ -4,6 +4,14 @@ import (
 	"time"
 )
 
+// Saga modes of the booking flow.
+const (
+	// SagaChoreography: participants react to each other's events.
+	SagaChoreography = "choreography"
+	// SagaOrchestration: the orchestrator drives participants with commands.
+	SagaOrchestration = "orchestration"
+)
+
 type Order struct {
 	ID          string    `json:"id"`
 	UserID      string    `json:"user_id"`
@@ -14,6 +22,7 @@ type Order struct {
 	TravelDate  string    `json:"travel_date"` // YYYY-MM-DD
 	TravelTime  string    `json:"travel_time"` // HH:MM
 	Airline     string    `json:"airline"`
+	SagaMode    string    `json:"saga_mode"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -0,0 +1,79 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	StatusCreated              = "CREATED"
+	StatusPaymentAuthorized    = "PAYMENT_AUTHORIZED"
+	StatusTicketIssued         = "TICKET_ISSUED"
+	StatusCancelled            = "CANCELLED"
+	StatusCompensating         = "COMPENSATING"
+	StatusCancelledCompensated = "CANCELLED_COMPENSATED"
+	StatusRefundPending        = "REFUND_PENDING"
+	StatusRefunded             = "REFUNDED"
+)
+
+var (
+	ErrNotFound          = errors.New("order not found")
+	ErrInvalidTransition = errors.New("invalid order status transition")
+)
+
+// transitions lists, for each status, the statuses an order may move to from it.
+var transitions = map[string][]string{
+	StatusCreated:           {StatusPaymentAuthorized, StatusCancelled},
+	StatusPaymentAuthorized: {StatusTicketIssued, StatusCompensating},
+	StatusCompensating:      {StatusCancelledCompensated},
+	StatusTicketIssued:      {StatusRefundPending},
+	StatusRefundPending:     {StatusRefunded},
+}
+
+// IsAbandoned reports whether the saga of an order in status was cancelled or is
+// being compensated, so late replies of the forward flow no longer apply to it.
+func IsAbandoned(status string) bool {
+	switch status {
+	case StatusCancelled, StatusCompensating, StatusCancelledCompensated:
+		return true
+	}
+	return false
+}
+
+// TransitionError reports a transition the state machine does not allow.
+type TransitionError struct {
+	OrderID string
+	From    string
+	To      string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("order %s: cannot move from %s to %s", e.OrderID, e.From, e.To)
+}
+
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrInvalidTransition
+}
+
+// CanTransition reports whether an order in status from may move to status to.
+func CanTransition(from, to string) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// SourcesOf returns the statuses from which an order may move to status to.
+func SourcesOf(to string) []string {
+	var sources []string
+	for from, targets := range transitions {
+		for _, s := range targets {
+			if s == to {
+				sources = append(sources, from)
+			}
+		}
+	}
+	return sources
+}
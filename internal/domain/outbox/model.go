@@ -5,20 +5,39 @@ import (
 	"time"
 )
 
+// Partitions is the number of relay partitions; it matches the modulus of the
+// generated outbox.partition_key column.
+const Partitions = 64
+
 type Event struct {
-	ID            string    `json:"id"`
-	EventType     string    `json:"event_type"`
-	Payload       []byte    `json:"payload"`
-	Status        string    `json:"status"`
-	CorrelationID string    `json:"correlation_id"`
-	CausationID   string    `json:"causation_id"`
-	Producer      string    `json:"producer"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            string `json:"id"`
+	EventType     string `json:"event_type"`
+	Payload       []byte `json:"payload"`
+	Status        string `json:"status"`
+	CorrelationID string `json:"correlation_id"`
+	CausationID   string `json:"causation_id"`
+	Producer      string `json:"producer"`
+	// SchemaVersion is the version of the payload schema; Create fills in the
+	// catalog version of EventType when it is 0.
+	SchemaVersion  int        `json:"schema_version"`
+	ClaimedBy      string     `json:"claimed_by,omitempty"`
+	LeaseExpiresAt *time.Time `json:"lease_expires_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
 }
 
 type Repository interface {
 	Create(ctx context.Context, event *Event) error
-	FetchBatch(ctx context.Context, limit int) ([]*Event, error)
-	MarkProcessed(ctx context.Context, ids []string) error
+	// FetchBatch claims up to limit 'new' events of the given partitions (nil for all)
+	// for workerID until the lease expires, at most the oldest unpublished event of
+	// each aggregate.
+	FetchBatch(ctx context.Context, workerID string, partitions []int, limit int, lease time.Duration) ([]*Event, error)
+	// MarkProcessed and MarkFailed settle events still claimed by workerID and
+	// return how many they settled; events whose lease was lost are skipped.
+	MarkProcessed(ctx context.Context, workerID string, ids []string) (int64, error)
+	MarkFailed(ctx context.Context, workerID string, ids []string) (int64, error)
+	// FilterUnprocessed returns the ids that are not yet marked 'processed'.
+	FilterUnprocessed(ctx context.Context, ids []string) ([]string, error)
+	// ReclaimExpired returns events whose lease expired while 'processing' back to 'new'.
+	ReclaimExpired(ctx context.Context) (int64, error)
 }
@@ -2,11 +2,29 @@ package payment
 
 import "time"
 
+const (
+	StatusAuthorized = "AUTHORIZED"
+	StatusFailed     = "FAILED"
+	StatusVoided     = "VOIDED"
+	StatusRefunded   = "REFUNDED"
+)
+
 type Payment struct {
+	ID            string    `json:"id"`
+	OrderID       string    `json:"order_id"`
+	Status        string    `json:"status"`
+	Amount        float64   `json:"amount"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Refund records money returned to the customer for a refunded order.
+type Refund struct {
 	ID        string    `json:"id"`
+	PaymentID string    `json:"payment_id"`
 	OrderID   string    `json:"order_id"`
-	Status    string    `json:"status"`
 	Amount    float64   `json:"amount"`
+	Reason    string    `json:"reason,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
 }
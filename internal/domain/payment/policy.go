@@ -0,0 +1,109 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// Reason codes carried by PaymentFailed and stored on the payments row.
+const (
+	ReasonAmountLimitExceeded = "AMOUNT_LIMIT_EXCEEDED"
+	ReasonInsufficientFunds   = "INSUFFICIENT_FUNDS"
+	ReasonGatewayDeclined     = "GATEWAY_DECLINED"
+)
+
+// AuthorizationRequest is what a policy decides on.
+type AuthorizationRequest struct {
+	OrderID string
+	UserID  string
+	Amount  float64
+}
+
+// Decision is the outcome of a policy. ReasonCode is empty when approved.
+type Decision struct {
+	Approved   bool
+	ReasonCode string
+	Reason     string
+}
+
+func Approve() Decision {
+	return Decision{Approved: true}
+}
+
+func Decline(code, reason string) Decision {
+	return Decision{ReasonCode: code, Reason: reason}
+}
+
+// Policy decides whether a payment may be authorized.
+// An error means the decision could not be made and should be retried.
+type Policy interface {
+	Authorize(ctx context.Context, req AuthorizationRequest) (Decision, error)
+}
+
+// Chain runs policies in order; the first decline wins.
+type Chain []Policy
+
+func (c Chain) Authorize(ctx context.Context, req AuthorizationRequest) (Decision, error) {
+	for _, p := range c {
+		d, err := p.Authorize(ctx, req)
+		if err != nil {
+			return Decision{}, err
+		}
+		if !d.Approved {
+			return d, nil
+		}
+	}
+	return Approve(), nil
+}
+
+// AmountLimit declines payments above Max.
+type AmountLimit struct {
+	Max float64
+}
+
+func (p AmountLimit) Authorize(_ context.Context, req AuthorizationRequest) (Decision, error) {
+	if p.Max > 0 && req.Amount > p.Max {
+		return Decline(ReasonAmountLimitExceeded, fmt.Sprintf("amount %.2f exceeds limit %.2f", req.Amount, p.Max)), nil
+	}
+	return Approve(), nil
+}
+
+// BalanceReader returns the available balance of a user.
+type BalanceReader interface {
+	Balance(ctx context.Context, userID string) (float64, error)
+}
+
+// SufficientBalance declines payments the user cannot cover from the ledger.
+type SufficientBalance struct {
+	Ledger BalanceReader
+}
+
+func (p SufficientBalance) Authorize(ctx context.Context, req AuthorizationRequest) (Decision, error) {
+	balance, err := p.Ledger.Balance(ctx, req.UserID)
+	if err != nil {
+		return Decision{}, fmt.Errorf("read balance: %w", err)
+	}
+	if balance < req.Amount {
+		return Decline(ReasonInsufficientFunds, fmt.Sprintf("balance %.2f is less than %.2f", balance, req.Amount)), nil
+	}
+	return Approve(), nil
+}
+
+// FakeGateway is a deterministic stand-in for an acquiring bank: it declines
+// every amount whose cents equal DeclineCents (e.g. 100.13 with DeclineCents=13),
+// so the failure branch of the saga can be triggered on purpose.
+type FakeGateway struct {
+	DeclineCents int
+}
+
+func (p FakeGateway) Authorize(_ context.Context, req AuthorizationRequest) (Decision, error) {
+	if p.DeclineCents <= 0 {
+		return Approve(), nil
+	}
+	cents := int(math.Round(req.Amount*100)) % 100
+	if cents == p.DeclineCents {
+		return Decline(ReasonGatewayDeclined, fmt.Sprintf("gateway declined amount %.2f", req.Amount)), nil
+	}
+	return Approve(), nil
+}
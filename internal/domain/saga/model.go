@@ -0,0 +1,40 @@
+package saga
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Instance is the persisted state of one orchestrated saga.
+// Command and CommandPayload keep the last command sent, so it can be resent
+// when no reply arrives before DeadlineAt.
+type Instance struct {
+	ID             string          `json:"id"`
+	Flow           string          `json:"flow"`
+	OrderID        string          `json:"order_id"`
+	Step           string          `json:"step"`
+	State          string          `json:"state"`
+	Retries        int             `json:"retries"`
+	Command        string          `json:"command,omitempty"`
+	CommandPayload json.RawMessage `json:"command_payload,omitempty"`
+	DeadlineAt     *time.Time      `json:"deadline_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// StepDone is the step of a finished saga. The other steps come from the
+// flow definition (internal/saga).
+const StepDone = "DONE"
+
+const (
+	StateRunning      = "RUNNING"
+	StateCompensating = "COMPENSATING"
+	StateCompleted    = "COMPLETED"
+	StateCompensated  = "COMPENSATED"
+	StateFailed       = "FAILED"
+)
+
+// IsActive reports whether the saga still waits for a reply.
+func (i *Instance) IsActive() bool {
+	return i.State == StateRunning || i.State == StateCompensating
+}
@@ -0,0 +1,68 @@
+package ticket
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Reason codes carried by TicketFailed and stored on the tickets row.
+const (
+	ReasonSeatsUnavailable = "SEATS_UNAVAILABLE"
+	ReasonAirlineRejected  = "AIRLINE_REJECTED"
+)
+
+// Flight identifies the seats a ticket is issued for.
+type Flight struct {
+	FromCity   string
+	ToCity     string
+	TravelDate string
+	TravelTime string
+	Airline    string
+}
+
+// BookingResult is the airline's answer. ReasonCode is empty when confirmed.
+type BookingResult struct {
+	Confirmed  bool
+	ReasonCode string
+	Reason     string
+}
+
+// Airline books a seat on a flight.
+// An error means the airline could not be reached and the booking should be retried.
+type Airline interface {
+	Book(ctx context.Context, f Flight) (BookingResult, error)
+}
+
+// SeatCounter returns the number of tickets already issued for a flight.
+type SeatCounter interface {
+	CountIssued(ctx context.Context, f Flight) (int, error)
+}
+
+// FakeAirline is a deterministic stand-in for an airline booking system:
+// each flight has Capacity seats, and airlines listed in Rejecting refuse every booking.
+type FakeAirline struct {
+	Seats     SeatCounter
+	Capacity  int
+	Rejecting []string
+}
+
+func (a FakeAirline) Book(ctx context.Context, f Flight) (BookingResult, error) {
+	for _, name := range a.Rejecting {
+		if strings.EqualFold(strings.TrimSpace(name), f.Airline) {
+			return BookingResult{ReasonCode: ReasonAirlineRejected, Reason: fmt.Sprintf("airline %q rejected the booking", f.Airline)}, nil
+		}
+	}
+
+	if a.Capacity > 0 {
+		issued, err := a.Seats.CountIssued(ctx, f)
+		if err != nil {
+			return BookingResult{}, fmt.Errorf("count issued tickets: %w", err)
+		}
+		if issued >= a.Capacity {
+			return BookingResult{ReasonCode: ReasonSeatsUnavailable, Reason: fmt.Sprintf("all %d seats are taken", a.Capacity)}, nil
+		}
+	}
+
+	return BookingResult{Confirmed: true}, nil
+}
@@ -2,52 +2,311 @@ package grpc
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"project/internal/domain/inbox"
+	"project/internal/domain/order"
+	"project/internal/domain/outbox"
+	"project/internal/domain/payment"
+	"project/internal/domain/ticket"
+	pb "project/internal/grpc/proto"
+	"project/internal/saga"
 	"project/internal/usecase"
 
+	"github.com/google/uuid"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-// Mocking the generated code interface for simplicity in this environment
-// In reality, this would be generated by protoc-gen-go-grpc
+// ServiceServer implements the OrderService of api/proto/order.proto on top
+// of the same use cases as the REST API.
+type ServiceServer struct {
+	pb.UnimplementedOrderServiceServer
 
-type CreateOrderRequest struct {
-	UserID string
-	Amount float64
+	createOrderUC *usecase.CreateOrder
+	getOrderUC    *usecase.GetOrder
+	getWorkflowUC *usecase.GetWorkflow
+	refundOrderUC *usecase.RefundOrder
+	watchOrderUC  *usecase.WatchOrder
 }
 
-type CreateOrderResponse struct {
-	OrderID string
-	Status  string
+func NewServiceServer(createOrderUC *usecase.CreateOrder, getOrderUC *usecase.GetOrder, getWorkflowUC *usecase.GetWorkflow, refundOrderUC *usecase.RefundOrder, watchOrderUC *usecase.WatchOrder) *ServiceServer {
+	return &ServiceServer{
+		createOrderUC: createOrderUC,
+		getOrderUC:    getOrderUC,
+		getWorkflowUC: getWorkflowUC,
+		refundOrderUC: refundOrderUC,
+		watchOrderUC:  watchOrderUC,
+	}
 }
 
-// ServiceServer is the implementation (normally embeds UnimplementedOrderServiceServer)
-type ServiceServer struct {
-	useCase *usecase.CreateOrder
+// Register adds srv to s.
+func Register(s *grpc.Server, srv *ServiceServer) {
+	pb.RegisterOrderServiceServer(s, srv)
 }
 
-func NewServiceServer(uc *usecase.CreateOrder) *ServiceServer {
-	return &ServiceServer{useCase: uc}
+func (s *ServiceServer) CreateOrder(ctx context.Context, req *pb.CreateOrderRequest) (*pb.CreateOrderResponse, error) {
+	id, err := s.createOrderUC.Execute(ctx, usecase.CreateOrderParams{
+		UserID:   req.GetUserId(),
+		Amount:   req.GetAmount(),
+		From:     req.GetFrom(),
+		To:       req.GetTo(),
+		Date:     req.GetDate(),
+		Time:     req.GetTime(),
+		Airline:  req.GetAirline(),
+		SagaMode: req.GetSagaMode(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.CreateOrderResponse{
+		OrderId: id,
+		Status:  "CREATED",
+	}, nil
 }
 
-func (s *ServiceServer) CreateOrder(ctx context.Context, req *CreateOrderRequest) (*CreateOrderResponse, error) {
-	// In a real gRPC handler, we map proto types to domain types
-	id, err := s.useCase.Execute(ctx, usecase.CreateOrderParams{
-		UserID: req.UserID,
-		Amount: req.Amount,
-	})
+func (s *ServiceServer) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.Order, error) {
+	if err := validateOrderID(req.GetOrderId()); err != nil {
+		return nil, err
+	}
 
+	o, err := s.getOrderUC.Execute(ctx, req.GetOrderId())
 	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toOrder(o), nil
+}
+
+func (s *ServiceServer) GetWorkflow(ctx context.Context, req *pb.GetWorkflowRequest) (*pb.Workflow, error) {
+	if err := validateOrderID(req.GetOrderId()); err != nil {
 		return nil, err
 	}
 
-	return &CreateOrderResponse{
-		OrderID: id,
-		Status:  "created",
-	}, nil
+	workflow, err := s.getWorkflowUC.Execute(ctx, req.GetOrderId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toWorkflow(workflow), nil
 }
 
-// Register would normally use the generated RegisterOrderServiceServer
-func Register(s *grpc.Server, srv *ServiceServer) {
-	// s.RegisterService(...) - skipping actual registration call because generated code is missing
+func (s *ServiceServer) RefundOrder(ctx context.Context, req *pb.RefundOrderRequest) (*pb.RefundOrderResponse, error) {
+	if err := validateOrderID(req.GetOrderId()); err != nil {
+		return nil, err
+	}
+
+	err := s.refundOrderUC.Execute(ctx, usecase.RefundOrderParams{
+		OrderID: req.GetOrderId(),
+		Reason:  req.GetReason(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.RefundOrderResponse{Status: "refund_initiated"}, nil
+}
+
+func (s *ServiceServer) WatchOrder(req *pb.WatchOrderRequest, stream grpc.ServerStreamingServer[pb.OrderUpdate]) error {
+	if err := validateOrderID(req.GetOrderId()); err != nil {
+		return err
+	}
+
+	err := s.watchOrderUC.Execute(stream.Context(), req.GetOrderId(), func(u *usecase.OrderUpdateDTO) error {
+		return stream.Send(toOrderUpdate(u))
+	})
+	if err != nil {
+		return toStatus(err)
+	}
+	return nil
+}
+
+// validateOrderID rejects ids that are not UUIDs before they reach Postgres,
+// which would fail the query with an internal error.
+func validateOrderID(id string) error {
+	if id == "" {
+		return status.Error(codes.InvalidArgument, "missing order id")
+	}
+	if err := uuid.Validate(id); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid order id %q: %v", id, err)
+	}
+	return nil
+}
+
+// toStatus maps use case errors to gRPC status codes, like writeOrderError
+// does for HTTP.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, order.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, order.ErrInvalidTransition):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, usecase.ErrInvalidSagaMode):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func toOrder(o *usecase.OrderDTO) *pb.Order {
+	return &pb.Order{
+		Id:          o.ID,
+		UserId:      o.UserID,
+		TotalAmount: o.TotalAmount,
+		Status:      o.Status,
+		FromCity:    o.FromCity,
+		ToCity:      o.ToCity,
+		TravelDate:  o.TravelDate,
+		TravelTime:  o.TravelTime,
+		Airline:     o.Airline,
+		SagaMode:    o.SagaMode,
+		CreatedAt:   timestamp(o.CreatedAt),
+	}
+}
+
+func toWorkflow(w *usecase.WorkflowDTO) *pb.Workflow {
+	out := &pb.Workflow{
+		Order:   toOrder(w.Order),
+		Payment: toPayment(w.Payment),
+		Ticket:  toTicket(w.Ticket),
+		Refund:  toRefund(w.Refund),
+	}
+	for _, e := range w.Outbox {
+		out.Outbox = append(out.Outbox, toOutboxEvent(e))
+	}
+	for _, e := range w.Inbox {
+		out.Inbox = append(out.Inbox, toInboxEvent(e))
+	}
+	for _, h := range w.History {
+		out.History = append(out.History, toHistoryEntry(h))
+	}
+	for _, p := range w.Progress {
+		out.Progress = append(out.Progress, toStepProgress(p))
+	}
+	return out
+}
+
+func toOrderUpdate(u *usecase.OrderUpdateDTO) *pb.OrderUpdate {
+	switch {
+	case u.Order != nil:
+		return &pb.OrderUpdate{Update: &pb.OrderUpdate_Order{Order: toOrder(u.Order)}}
+	case u.StatusChange != nil:
+		return &pb.OrderUpdate{Update: &pb.OrderUpdate_StatusChange{StatusChange: toHistoryEntry(u.StatusChange)}}
+	case u.Outbox != nil:
+		return &pb.OrderUpdate{Update: &pb.OrderUpdate_Outbox{Outbox: toOutboxEvent(u.Outbox)}}
+	case u.Inbox != nil:
+		return &pb.OrderUpdate{Update: &pb.OrderUpdate_Inbox{Inbox: toInboxEvent(u.Inbox)}}
+	}
+	return &pb.OrderUpdate{}
+}
+
+func toHistoryEntry(h *usecase.HistoryEntryDTO) *pb.HistoryEntry {
+	return &pb.HistoryEntry{
+		FromStatus:      h.FromStatus,
+		ToStatus:        h.ToStatus,
+		ChangedBy:       h.ChangedBy,
+		EventType:       h.EventType,
+		CausationId:     h.CausationID,
+		ChangedAt:       timestamp(h.ChangedAt),
+		SincePreviousMs: h.SincePreviousMs,
+	}
+}
+
+func toOutboxEvent(e *outbox.Event) *pb.OutboxEvent {
+	return &pb.OutboxEvent{
+		Id:            e.ID,
+		EventType:     e.EventType,
+		Payload:       e.Payload,
+		Status:        e.Status,
+		CorrelationId: e.CorrelationID,
+		CausationId:   e.CausationID,
+		Producer:      e.Producer,
+		SchemaVersion: int32(e.SchemaVersion),
+		CreatedAt:     timestamp(e.CreatedAt),
+		UpdatedAt:     timestamp(e.UpdatedAt),
+	}
+}
+
+func toInboxEvent(e *inbox.Event) *pb.InboxEvent {
+	return &pb.InboxEvent{
+		Consumer:      e.Consumer,
+		EventId:       e.EventID,
+		EventType:     e.EventType,
+		CorrelationId: e.CorrelationID,
+		ProcessedAt:   timestamp(e.ProcessedAt),
+	}
+}
+
+func toPayment(p *payment.Payment) *pb.Payment {
+	if p == nil {
+		return nil
+	}
+	return &pb.Payment{
+		Id:            p.ID,
+		Status:        p.Status,
+		Amount:        p.Amount,
+		FailureReason: p.FailureReason,
+		CreatedAt:     timestamp(p.CreatedAt),
+		UpdatedAt:     timestamp(p.UpdatedAt),
+	}
+}
+
+func toTicket(t *ticket.Ticket) *pb.Ticket {
+	if t == nil {
+		return nil
+	}
+	return &pb.Ticket{
+		Id:            t.ID,
+		FromCity:      t.FromCity,
+		ToCity:        t.ToCity,
+		TravelDate:    t.TravelDate,
+		TravelTime:    t.TravelTime,
+		Airline:       t.Airline,
+		Status:        t.Status,
+		FailureReason: t.FailureReason,
+		CreatedAt:     timestamp(t.CreatedAt),
+		UpdatedAt:     timestamp(t.UpdatedAt),
+	}
+}
+
+func toRefund(r *payment.Refund) *pb.Refund {
+	if r == nil {
+		return nil
+	}
+	return &pb.Refund{
+		Id:        r.ID,
+		PaymentId: r.PaymentID,
+		Amount:    r.Amount,
+		Reason:    r.Reason,
+		CreatedAt: timestamp(r.CreatedAt),
+	}
+}
+
+func toStepProgress(p saga.StepProgress) *pb.StepProgress {
+	out := &pb.StepProgress{
+		Step:        p.Step,
+		Participant: p.Participant,
+		Expected:    p.Expected,
+		State:       p.State,
+		Event:       p.Event,
+	}
+	if p.At != nil {
+		out.At = timestamp(*p.At)
+	}
+	return out
+}
+
+// timestamp leaves zero times unset instead of sending 0001-01-01.
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
 }
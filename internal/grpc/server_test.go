@@ -0,0 +1,279 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"project/internal/domain/inbox"
+	"project/internal/domain/order"
+	"project/internal/domain/outbox"
+	pb "project/internal/grpc/proto"
+	"project/internal/usecase"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testOrderID = "7b0c3f0e-4a53-4a43-9c5b-6f1f1d1c2a10"
+
+// fakeStore is an in-memory stand-in for the order, outbox and inbox tables
+// and their notifications.
+type fakeStore struct {
+	mu      sync.Mutex
+	orders  map[string]*order.Order
+	history []*order.HistoryEntry
+	outbox  []*outbox.Event
+	inbox   []*inbox.Event
+	subs    []chan struct{}
+}
+
+func (s *fakeStore) GetByID(_ context.Context, id string) (*order.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.orders[id]
+	if !ok {
+		return nil, order.ErrNotFound
+	}
+	return o, nil
+}
+
+func (s *fakeStore) ListHistory(context.Context, string) ([]*order.HistoryEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.history), nil
+}
+
+func (s *fakeStore) Subscribe(string) (<-chan struct{}, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := make(chan struct{}, 1)
+	s.subs = append(s.subs, ch)
+	return ch, func() {}
+}
+
+// change applies fn and notifies the subscribers, like a committed write.
+func (s *fakeStore) change(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fn()
+	for _, ch := range s.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+type fakeOutbox struct{ *fakeStore }
+
+func (s fakeOutbox) ListByCorrelationID(context.Context, string) ([]*outbox.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := make([]*outbox.Event, 0, len(s.outbox))
+	for _, e := range s.outbox {
+		copied := *e
+		events = append(events, &copied)
+	}
+	return events, nil
+}
+
+type fakeInbox struct{ *fakeStore }
+
+func (s fakeInbox) ListByCorrelationID(context.Context, string) ([]*inbox.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.inbox), nil
+}
+
+// newTestClient serves a ServiceServer backed by store over bufconn.
+func newTestClient(t *testing.T, store *fakeStore) pb.OrderServiceClient {
+	t.Helper()
+
+	watchOrderUC := usecase.NewWatchOrder(store, fakeOutbox{store}, fakeInbox{store}, store)
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	Register(srv, NewServiceServer(nil, nil, nil, nil, watchOrderUC))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial bufconn: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewOrderServiceClient(conn)
+}
+
+func newTestStore() *fakeStore {
+	createdAt := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	return &fakeStore{
+		orders: map[string]*order.Order{
+			testOrderID: {
+				ID:          testOrderID,
+				UserID:      "user-1",
+				Status:      order.StatusCreated,
+				TotalAmount: 120,
+				FromCity:    "Moscow",
+				ToCity:      "Kazan",
+				CreatedAt:   createdAt,
+			},
+		},
+		history: []*order.HistoryEntry{
+			{ID: 1, OrderID: testOrderID, ToStatus: order.StatusCreated, ChangedBy: "order-service", ChangedAt: createdAt},
+		},
+		outbox: []*outbox.Event{
+			{ID: "event-1", EventType: "OrderCreated", Status: "new", CorrelationID: testOrderID, CreatedAt: createdAt},
+		},
+	}
+}
+
+func recvUpdate(t *testing.T, stream grpc.ServerStreamingClient[pb.OrderUpdate]) *pb.OrderUpdate {
+	t.Helper()
+
+	u, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("recv: %v", err)
+	}
+	return u
+}
+
+func TestWatchOrder(t *testing.T) {
+	store := newTestStore()
+	client := newTestClient(t, store)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchOrder(ctx, &pb.WatchOrderRequest{OrderId: testOrderID})
+	if err != nil {
+		t.Fatalf("WatchOrder: %v", err)
+	}
+
+	// The snapshot, then what was already recorded.
+	if o := recvUpdate(t, stream).GetOrder(); o.GetId() != testOrderID || o.GetStatus() != order.StatusCreated {
+		t.Fatalf("first update = order %v, want the %s snapshot", o, order.StatusCreated)
+	}
+	if h := recvUpdate(t, stream).GetStatusChange(); h.GetToStatus() != order.StatusCreated {
+		t.Fatalf("second update = status change %v, want to %s", h, order.StatusCreated)
+	}
+	if e := recvUpdate(t, stream).GetOutbox(); e.GetId() != "event-1" || e.GetStatus() != "new" {
+		t.Fatalf("third update = outbox %v, want event-1 new", e)
+	}
+
+	store.change(func() {
+		changedAt := time.Date(2026, 1, 2, 10, 0, 1, 0, time.UTC)
+		store.outbox[0].Status = "processed"
+		store.inbox = append(store.inbox, &inbox.Event{
+			Consumer: "payment-service", EventID: "event-1", EventType: "OrderCreated",
+			CorrelationID: testOrderID, ProcessedAt: changedAt,
+		})
+		store.history = append(store.history, &order.HistoryEntry{
+			ID: 2, OrderID: testOrderID, FromStatus: order.StatusCreated, ToStatus: order.StatusPaymentAuthorized,
+			ChangedBy: "order-service", EventType: "PaymentAuthorized", ChangedAt: changedAt,
+		})
+	})
+
+	h := recvUpdate(t, stream).GetStatusChange()
+	if h.GetFromStatus() != order.StatusCreated || h.GetToStatus() != order.StatusPaymentAuthorized {
+		t.Errorf("status change = %v, want %s -> %s", h, order.StatusCreated, order.StatusPaymentAuthorized)
+	}
+	if h.GetSincePreviousMs() != 1000 {
+		t.Errorf("since_previous_ms = %d, want 1000", h.GetSincePreviousMs())
+	}
+	if e := recvUpdate(t, stream).GetOutbox(); e.GetId() != "event-1" || e.GetStatus() != "processed" {
+		t.Errorf("outbox update = %v, want event-1 processed", e)
+	}
+	if e := recvUpdate(t, stream).GetInbox(); e.GetConsumer() != "payment-service" || e.GetEventId() != "event-1" {
+		t.Errorf("inbox update = %v, want event-1 at payment-service", e)
+	}
+
+	// A wake-up without a change sends nothing.
+	store.change(func() {})
+	store.change(func() {
+		store.outbox = append(store.outbox, &outbox.Event{
+			ID: "event-2", EventType: "IssueTicket", Status: "new", CorrelationID: testOrderID,
+		})
+	})
+	if e := recvUpdate(t, stream).GetOutbox(); e.GetId() != "event-2" {
+		t.Errorf("update after an empty wake-up = %v, want outbox event-2", e)
+	}
+}
+
+func TestWatchOrderNotFound(t *testing.T) {
+	client := newTestClient(t, newTestStore())
+
+	stream, err := client.WatchOrder(context.Background(), &pb.WatchOrderRequest{OrderId: "00000000-0000-0000-0000-000000000000"})
+	if err != nil {
+		t.Fatalf("WatchOrder: %v", err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.NotFound {
+		t.Fatalf("recv: %v, want code NotFound", err)
+	}
+}
+
+func TestInvalidOrderID(t *testing.T) {
+	client := newTestClient(t, newTestStore())
+	ctx := context.Background()
+
+	for _, id := range []string{"", "42", "not-a-uuid"} {
+		_, err := client.GetOrder(ctx, &pb.GetOrderRequest{OrderId: id})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("GetOrder(%q): %v, want code InvalidArgument", id, err)
+		}
+
+		_, err = client.GetWorkflow(ctx, &pb.GetWorkflowRequest{OrderId: id})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("GetWorkflow(%q): %v, want code InvalidArgument", id, err)
+		}
+
+		_, err = client.RefundOrder(ctx, &pb.RefundOrderRequest{OrderId: id})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("RefundOrder(%q): %v, want code InvalidArgument", id, err)
+		}
+
+		stream, err := client.WatchOrder(ctx, &pb.WatchOrderRequest{OrderId: id})
+		if err == nil {
+			_, err = stream.Recv()
+		}
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("WatchOrder(%q): %v, want code InvalidArgument", id, err)
+		}
+	}
+}
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{order.ErrNotFound, codes.NotFound},
+		{&order.TransitionError{OrderID: testOrderID, From: order.StatusCreated, To: order.StatusRefunded}, codes.FailedPrecondition},
+		{usecase.ErrInvalidSagaMode, codes.InvalidArgument},
+		{context.Canceled, codes.Canceled},
+		{status.Error(codes.Unavailable, "down"), codes.Unavailable},
+		{net.ErrClosed, codes.Internal},
+	}
+	for _, tt := range tests {
+		if got := status.Code(toStatus(tt.err)); got != tt.want {
+			t.Errorf("toStatus(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Package eventcodec encodes the event envelope for Kafka. The content type
+// travels in the content-type header, so consumers decode every message with
+// the codec it was written with and topics can switch codecs at any time.
+package eventcodec
+
+import (
+	"fmt"
+
+	domainEvent "project/internal/domain/event"
+)
+
+// Content types of encoded envelopes.
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// Codec names used in the configuration.
+const (
+	NameJSON     = "json"
+	NameProtobuf = "protobuf"
+)
+
+// Codec converts the envelope to and from the bytes of a Kafka message.
+// Decode always returns the payload as JSON, so handlers are codec-agnostic.
+type Codec interface {
+	ContentType() string
+	Encode(m domainEvent.Message) ([]byte, error)
+	Decode(data []byte) (domainEvent.Message, error)
+}
+
+var byContentType = map[string]Codec{
+	ContentTypeJSON:     JSON{},
+	ContentTypeProtobuf: Protobuf{},
+}
+
+// ByName returns the codec for a configuration value, json or protobuf.
+func ByName(name string) (Codec, error) {
+	switch name {
+	case NameJSON, "":
+		return JSON{}, nil
+	case NameProtobuf:
+		return Protobuf{}, nil
+	}
+	return nil, fmt.Errorf("unknown event codec %q", name)
+}
+
+// Decode decodes data with the codec of contentType. Messages published
+// before the header existed are JSON.
+func Decode(contentType string, data []byte) (domainEvent.Message, error) {
+	if contentType == "" {
+		contentType = ContentTypeJSON
+	}
+	c, ok := byContentType[contentType]
+	if !ok {
+		return domainEvent.Message{}, fmt.Errorf("unsupported content type %q", contentType)
+	}
+	return c.Decode(data)
+}
+
+// Selector picks the codec of each topic: high-volume topics can be switched
+// to protobuf while the rest stay JSON. The nil Selector uses JSON everywhere.
+type Selector struct {
+	def    Codec
+	topics map[string]Codec
+}
+
+func NewSelector(defaultCodec string, topicCodecs map[string]string) (*Selector, error) {
+	def, err := ByName(defaultCodec)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Selector{def: def, topics: make(map[string]Codec, len(topicCodecs))}
+	for topic, name := range topicCodecs {
+		c, err := ByName(name)
+		if err != nil {
+			return nil, fmt.Errorf("topic %s: %w", topic, err)
+		}
+		s.topics[topic] = c
+	}
+	return s, nil
+}
+
+// ForTopic returns the codec messages to topic are written with.
+func (s *Selector) ForTopic(topic string) Codec {
+	if s == nil {
+		return JSON{}
+	}
+	if c, ok := s.topics[topic]; ok {
+		return c
+	}
+	return s.def
+}
@@ -0,0 +1,31 @@
+package eventcodec
+
+import (
+	"encoding/json"
+	"fmt"
+
+	domainEvent "project/internal/domain/event"
+)
+
+// JSON is the default codec: the envelope as a JSON object.
+type JSON struct{}
+
+func (JSON) ContentType() string {
+	return ContentTypeJSON
+}
+
+func (JSON) Encode(m domainEvent.Message) ([]byte, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("marshal event: %w", err)
+	}
+	return data, nil
+}
+
+func (JSON) Decode(data []byte) (domainEvent.Message, error) {
+	var m domainEvent.Message
+	if err := json.Unmarshal(data, &m); err != nil {
+		return domainEvent.Message{}, fmt.Errorf("unmarshal event: %w", err)
+	}
+	return m, nil
+}
@@ -0,0 +1,118 @@
+package eventcodec
+
+import (
+	"fmt"
+
+	domainEvent "project/internal/domain/event"
+	"project/internal/infrastructure/eventcodec/eventpb"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type payloadKey struct {
+	eventType string
+	version   int
+}
+
+// payloadMessages maps catalog payloads to their messages in api/proto/events.proto.
+// A version without a message, or a payload that does not fit its message, is
+// sent as JSON inside the protobuf envelope.
+var payloadMessages = map[payloadKey]func() proto.Message{
+	{domainEvent.TypeOrderCreated, 1}:      func() proto.Message { return &eventpb.OrderCreated{} },
+	{domainEvent.TypeAuthorizePayment, 1}:  func() proto.Message { return &eventpb.OrderCreated{} },
+	{domainEvent.TypeOrderCreated, 2}:      func() proto.Message { return &eventpb.OrderCreatedV2{} },
+	{domainEvent.TypeAuthorizePayment, 2}:  func() proto.Message { return &eventpb.OrderCreatedV2{} },
+	{domainEvent.TypePaymentAuthorized, 1}: func() proto.Message { return &eventpb.PaymentAuthorized{} },
+	{domainEvent.TypeIssueTicket, 1}:       func() proto.Message { return &eventpb.PaymentAuthorized{} },
+	{domainEvent.TypePaymentFailed, 1}:     func() proto.Message { return &eventpb.PaymentFailed{} },
+	{domainEvent.TypePaymentTimedOut, 1}:   func() proto.Message { return &eventpb.SagaTimedOut{} },
+	{domainEvent.TypeTicketTimedOut, 1}:    func() proto.Message { return &eventpb.SagaTimedOut{} },
+	{domainEvent.TypeVoidPayment, 1}:       func() proto.Message { return &eventpb.Compensation{} },
+	{domainEvent.TypePaymentVoided, 1}:     func() proto.Message { return &eventpb.PaymentVoided{} },
+	{domainEvent.TypeTicketIssued, 1}:      func() proto.Message { return &eventpb.TicketIssued{} },
+	{domainEvent.TypeTicketFailed, 1}:      func() proto.Message { return &eventpb.TicketFailed{} },
+	{domainEvent.TypeRefundInitiated, 1}:   func() proto.Message { return &eventpb.RefundInitiated{} },
+	{domainEvent.TypePaymentRefunded, 1}:   func() proto.Message { return &eventpb.PaymentRefunded{} },
+	{domainEvent.TypeTicketCancelled, 1}:   func() proto.Message { return &eventpb.TicketCancelled{} },
+}
+
+// Protobuf encodes the envelope as eventpb.Envelope. Payloads are converted
+// field by field between their JSON form and the payload message, which share
+// field names.
+type Protobuf struct{}
+
+func (Protobuf) ContentType() string {
+	return ContentTypeProtobuf
+}
+
+func (Protobuf) Encode(m domainEvent.Message) ([]byte, error) {
+	env := &eventpb.Envelope{
+		Id:            m.ID,
+		Type:          m.Type,
+		CorrelationId: m.CorrelationID,
+		CausationId:   m.CausationID,
+		Producer:      m.Producer,
+		SchemaVersion: int32(m.SchemaVersion),
+		OccurredAt:    timestamppb.New(m.OccurredAt),
+	}
+
+	env.Payload = &eventpb.Envelope_JsonPayload{JsonPayload: m.Payload}
+	if newPayload, ok := payloadMessages[payloadKey{m.Type, m.Version()}]; ok && len(m.Payload) > 0 {
+		// A payload with fields the message does not have (a producer ahead of
+		// api/proto) is sent as JSON instead, so no field is dropped.
+		payload := newPayload()
+		if err := protojson.Unmarshal(m.Payload, payload); err == nil {
+			data, err := proto.Marshal(payload)
+			if err != nil {
+				return nil, fmt.Errorf("marshal %s payload: %w", m.Type, err)
+			}
+			env.Payload = &eventpb.Envelope_ProtoPayload{ProtoPayload: data}
+		}
+	}
+
+	data, err := proto.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("marshal event: %w", err)
+	}
+	return data, nil
+}
+
+func (Protobuf) Decode(data []byte) (domainEvent.Message, error) {
+	var env eventpb.Envelope
+	if err := proto.Unmarshal(data, &env); err != nil {
+		return domainEvent.Message{}, fmt.Errorf("unmarshal event: %w", err)
+	}
+
+	m := domainEvent.Message{
+		ID:            env.GetId(),
+		Type:          env.GetType(),
+		CorrelationID: env.GetCorrelationId(),
+		CausationID:   env.GetCausationId(),
+		Producer:      env.GetProducer(),
+		SchemaVersion: int(env.GetSchemaVersion()),
+		OccurredAt:    env.GetOccurredAt().AsTime(),
+	}
+
+	switch p := env.Payload.(type) {
+	case *eventpb.Envelope_JsonPayload:
+		m.Payload = p.JsonPayload
+	case *eventpb.Envelope_ProtoPayload:
+		newPayload, ok := payloadMessages[payloadKey{m.Type, m.Version()}]
+		if !ok {
+			return domainEvent.Message{}, fmt.Errorf("no protobuf message for %s v%d", m.Type, m.Version())
+		}
+		payload := newPayload()
+		if err := proto.Unmarshal(p.ProtoPayload, payload); err != nil {
+			return domainEvent.Message{}, fmt.Errorf("unmarshal %s payload: %w", m.Type, err)
+		}
+		payloadJSON, err := (protojson.MarshalOptions{UseProtoNames: true}).Marshal(payload)
+		if err != nil {
+			return domainEvent.Message{}, fmt.Errorf("convert %s payload: %w", m.Type, err)
+		}
+		m.Payload = payloadJSON
+	}
+
+	return m, nil
+}
@@ -0,0 +1,193 @@
+package eventcodec
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	domainEvent "project/internal/domain/event"
+	"project/internal/infrastructure/eventcodec/eventpb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+var sampleTime = time.Date(2026, 1, 2, 10, 30, 15, 0, time.UTC)
+
+// samplePayload returns a value of the payload type typ with every field set,
+// so that a field lost on the way shows up in the comparison.
+func samplePayload(t *testing.T, typ reflect.Type) any {
+	t.Helper()
+
+	v := reflect.New(typ).Elem()
+	for i := range typ.NumField() {
+		f := v.Field(i)
+		switch {
+		case f.Type() == reflect.TypeFor[time.Time]():
+			f.Set(reflect.ValueOf(sampleTime.Add(time.Duration(i) * time.Minute)))
+		case f.Kind() == reflect.String:
+			f.SetString(typ.Field(i).Name + "-value")
+		case f.Kind() == reflect.Float64:
+			f.SetFloat(float64(i) + 0.25)
+		default:
+			t.Fatalf("%s.%s: no sample for %s", typ.Name(), typ.Field(i).Name, f.Type())
+		}
+	}
+	return v.Interface()
+}
+
+func sampleMessage(eventType string, version int, payload json.RawMessage) domainEvent.Message {
+	return domainEvent.Message{
+		ID:            "0b7c1c5e-4f0e-4d55-9d41-2f1c8a3d9e01",
+		Type:          eventType,
+		CorrelationID: "7b0c3f0e-4a53-4a43-9c5b-6f1f1d1c2a10",
+		CausationID:   "c2d4a1f0-3b6e-4f7a-8e9d-0a1b2c3d4e5f",
+		Producer:      "test",
+		SchemaVersion: version,
+		OccurredAt:    sampleTime,
+		Payload:       payload,
+	}
+}
+
+// isProtoPayload reports whether data carries the payload as a protobuf message.
+func isProtoPayload(t *testing.T, data []byte) bool {
+	t.Helper()
+
+	var env eventpb.Envelope
+	if err := proto.Unmarshal(data, &env); err != nil {
+		t.Fatalf("unmarshal envelope: %v", err)
+	}
+	_, ok := env.Payload.(*eventpb.Envelope_ProtoPayload)
+	return ok
+}
+
+// TestProtobufRoundTrip converts every payload of the catalog from JSON to its
+// protobuf message and back and checks that the handler decodes the same
+// payload from both codecs.
+func TestProtobufRoundTrip(t *testing.T) {
+	for _, eventType := range domainEvent.Types() {
+		t.Run(eventType, func(t *testing.T) {
+			schema, _ := domainEvent.Lookup(eventType)
+			want, err := json.Marshal(samplePayload(t, schema.Payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			m := sampleMessage(eventType, schema.Version, want)
+
+			data, err := Protobuf{}.Encode(m)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			if !isProtoPayload(t, data) {
+				t.Fatalf("%s v%d was sent as JSON; add its message to api/proto/events.proto and payloadMessages", eventType, schema.Version)
+			}
+
+			got, err := Protobuf{}.Decode(data)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if got.ID != m.ID || got.Type != m.Type || got.CorrelationID != m.CorrelationID || got.CausationID != m.CausationID ||
+				got.Producer != m.Producer || got.SchemaVersion != m.SchemaVersion || !got.OccurredAt.Equal(m.OccurredAt) {
+				t.Errorf("envelope = %+v, want %+v", got, m)
+			}
+
+			fromJSON, err := JSON{}.Decode(mustEncode(t, JSON{}, m))
+			if err != nil {
+				t.Fatalf("JSON Decode: %v", err)
+			}
+			for codec, payload := range map[string]json.RawMessage{"protobuf": got.Payload, "json": fromJSON.Payload} {
+				decoded := reflect.New(schema.Payload)
+				if err := json.Unmarshal(payload, decoded.Interface()); err != nil {
+					t.Fatalf("%s: unmarshal %s: %v", codec, payload, err)
+				}
+				again, err := json.Marshal(decoded.Interface())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(again) != string(want) {
+					t.Errorf("%s payload:\n got %s\nwant %s", codec, again, want)
+				}
+			}
+		})
+	}
+}
+
+// OrderCreated v1 messages are still in the topics.
+func TestProtobufRoundTripOrderCreatedV1(t *testing.T) {
+	payload := json.RawMessage(`{"id":"o-1","user_id":"u-1","total_amount":100,"created_at":"2026-01-02T10:30:15Z"}`)
+
+	data, err := Protobuf{}.Encode(sampleMessage(domainEvent.TypeOrderCreated, 1, payload))
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if !isProtoPayload(t, data) {
+		t.Fatal("OrderCreated v1 was sent as JSON")
+	}
+
+	got, err := Protobuf{}.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	assertSameJSON(t, got.Payload, payload)
+}
+
+func TestProtobufUnknownFieldFallsBackToJSON(t *testing.T) {
+	payload := json.RawMessage(`{"order_id":"o-1","ticket_id":"t-1","seat":"12A"}`)
+
+	data, err := Protobuf{}.Encode(sampleMessage(domainEvent.TypeTicketIssued, 1, payload))
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if isProtoPayload(t, data) {
+		t.Fatal("payload with a field unknown to the message was sent as protobuf")
+	}
+
+	got, err := Protobuf{}.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if string(got.Payload) != string(payload) {
+		t.Errorf("payload = %s, want %s", got.Payload, payload)
+	}
+}
+
+func TestProtobufTypeOutsideCatalog(t *testing.T) {
+	payload := json.RawMessage(`{"anything":[1,2,3]}`)
+
+	data, err := Protobuf{}.Encode(sampleMessage("NotInCatalog", 1, payload))
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	got, err := Protobuf{}.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if string(got.Payload) != string(payload) {
+		t.Errorf("payload = %s, want %s", got.Payload, payload)
+	}
+}
+
+func mustEncode(t *testing.T, c Codec, m domainEvent.Message) []byte {
+	t.Helper()
+
+	data, err := c.Encode(m)
+	if err != nil {
+		t.Fatalf("%s Encode: %v", c.ContentType(), err)
+	}
+	return data
+}
+
+func assertSameJSON(t *testing.T, got, want json.RawMessage) {
+	t.Helper()
+
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("unmarshal %s: %v", got, err)
+	}
+	if err := json.Unmarshal(want, &w); err != nil {
+		t.Fatalf("unmarshal %s: %v", want, err)
+	}
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("payload = %s, want %s", got, want)
+	}
+}
@@ -2,6 +2,7 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"os"
 	"strings"
 	"time"
@@ -9,11 +10,27 @@ import (
 	"github.com/segmentio/kafka-go"
 )
 
+// Consumer reads a consumer group from a fixed topic, or from the topics a
+// Router assigns to the event types passed to Subscribe.
 type Consumer struct {
 	reader *kafka.Reader
+	cfg    kafka.ReaderConfig
+	router *Router
 }
 
 func NewConsumer(brokers []string, topic string, groupID string) *Consumer {
+	cfg := readerConfig(brokers, groupID)
+	cfg.Topic = topic
+	return &Consumer{reader: kafka.NewReader(cfg), cfg: cfg}
+}
+
+// NewRoutedConsumer creates a consumer that starts reading once Subscribe
+// tells it which event types it handles.
+func NewRoutedConsumer(brokers []string, router Router, groupID string) *Consumer {
+	return &Consumer{cfg: readerConfig(brokers, groupID), router: &router}
+}
+
+func readerConfig(brokers []string, groupID string) kafka.ReaderConfig {
 	startOffset := kafka.FirstOffset
 	// When a consumer group has no committed offset yet, kafka-go uses StartOffset.
 	// For demo purposes it's often useful to start from the latest message.
@@ -32,20 +49,44 @@ func NewConsumer(brokers []string, topic string, groupID string) *Consumer {
 		DualStack: false, // Force IPv4
 	}
 
-	r := kafka.NewReader(kafka.ReaderConfig{
+	return kafka.ReaderConfig{
 		Brokers:     brokers,
-		Topic:       topic,
 		GroupID:     groupID,
 		MinBytes:    1,    // Process immediately
 		MaxBytes:    10e6, // 10MB
 		MaxWait:     1 * time.Second,
 		Dialer:      dialer,
 		StartOffset: startOffset,
-	})
-	return &Consumer{reader: r}
+	}
+}
+
+// Subscribe starts reading the topics carrying eventTypes. It is a no-op for
+// a consumer created with a fixed topic or one that is already subscribed.
+func (c *Consumer) Subscribe(eventTypes []string) []string {
+	if c.reader != nil {
+		if c.cfg.Topic != "" {
+			return []string{c.cfg.Topic}
+		}
+		return c.cfg.GroupTopics
+	}
+
+	topics := c.router.Topics(eventTypes)
+	if len(topics) == 0 {
+		topics = []string{c.router.Topic("")}
+	}
+	if len(topics) == 1 {
+		c.cfg.Topic = topics[0]
+	} else {
+		c.cfg.GroupTopics = topics
+	}
+	c.reader = kafka.NewReader(c.cfg)
+	return topics
 }
 
 func (c *Consumer) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	if c.reader == nil {
+		return kafka.Message{}, errors.New("kafka consumer is not subscribed")
+	}
 	return c.reader.FetchMessage(ctx)
 }
 
@@ -54,5 +95,8 @@ func (c *Consumer) CommitMessages(ctx context.Context, msgs ...kafka.Message) er
 }
 
 func (c *Consumer) Close() error {
+	if c.reader == nil {
+		return nil
+	}
 	return c.reader.Close()
 }
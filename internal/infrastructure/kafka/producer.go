@@ -2,6 +2,7 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,42 +12,111 @@ import (
 type Config struct {
 	Brokers []string
 	Topic   string
+	// RequireAllAcks waits for all in-sync replicas before a write succeeds;
+	// otherwise the writer does not wait for any broker acknowledgement.
+	RequireAllAcks bool
+	// Router picks the topic of outbox events; without it everything goes to Topic.
+	Router *Router
 }
 
+// Producer writes to Topic unless a message names its own topic.
 type Producer struct {
 	writer *kafka.Writer
+	topic  string
+	router *Router
+}
+
+// Message is one record of a SendBatch call. An empty Topic means the
+// producer's default topic.
+type Message struct {
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
 }
 
 func NewProducer(cfg Config) *Producer {
+	// The topic is set per message so that one writer serves every route.
 	w := &kafka.Writer{
 		Addr:                   kafka.TCP(cfg.Brokers...),
-		Topic:                  cfg.Topic,
 		Balancer:               &kafka.Hash{},
 		MaxAttempts:            5,
 		ReadTimeout:            10 * time.Second,
 		WriteTimeout:           10 * time.Second,
 		Async:                  false,
 		AllowAutoTopicCreation: true,
+		// Flush as soon as a WriteMessages call is assigned instead of waiting
+		// for more messages: callers already hand over whole batches.
+		BatchTimeout: 10 * time.Millisecond,
+	}
+
+	if cfg.RequireAllAcks {
+		w.RequiredAcks = kafka.RequireAll
+	}
+
+	return &Producer{writer: w, topic: cfg.Topic, router: cfg.Router}
+}
+
+// SendBatch writes msgs in a single WriteMessages call and returns one error
+// per message, nil for the ones that were delivered.
+func (p *Producer) SendBatch(ctx context.Context, msgs []Message) []error {
+	results := make([]error, len(msgs))
+	if len(msgs) == 0 {
+		return results
 	}
 
-	return &Producer{writer: w}
+	batch := make([]kafka.Message, len(msgs))
+	for i, m := range msgs {
+		batch[i] = kafka.Message{Topic: m.Topic, Key: m.Key, Value: m.Value}
+		if batch[i].Topic == "" {
+			batch[i].Topic = p.topic
+		}
+		for k, v := range m.Headers {
+			batch[i].Headers = append(batch[i].Headers, kafka.Header{Key: k, Value: []byte(v)})
+		}
+	}
+
+	err := p.writer.WriteMessages(ctx, batch...)
+	if err == nil {
+		return results
+	}
+
+	var writeErrs kafka.WriteErrors
+	if errors.As(err, &writeErrs) && len(writeErrs) == len(msgs) {
+		for i, werr := range writeErrs {
+			if werr != nil {
+				results[i] = fmt.Errorf("failed to write message: %w", werr)
+			}
+		}
+		return results
+	}
+
+	for i := range results {
+		results[i] = fmt.Errorf("failed to write batch: %w", err)
+	}
+	return results
+}
+
+// SendBatchAsync starts SendBatch in the background and returns at once; done
+// receives its results. Calls from several goroutines share the writer's
+// batches, so the caller can claim the next outbox batch while this one is in
+// flight.
+func (p *Producer) SendBatchAsync(ctx context.Context, msgs []Message, done func(results []error)) {
+	go func() {
+		done(p.SendBatch(ctx, msgs))
+	}()
 }
 
-func (p *Producer) SendMessage(ctx context.Context, key, value []byte) error {
-	err := p.writer.WriteMessages(ctx,
-		kafka.Message{
-			Key:   key,
-			Value: value,
-		},
-	)
-	if err != nil {
-		return fmt.Errorf("failed to write message: %w", err)
+// Route returns the topic for events of eventType.
+func (p *Producer) Route(eventType string) string {
+	if p.router == nil {
+		return p.topic
 	}
-	return nil
+	return p.router.Topic(eventType)
 }
 
 func (p *Producer) GetTopic() string {
-	return p.writer.Topic
+	return p.topic
 }
 
 func (p *Producer) Close() error {
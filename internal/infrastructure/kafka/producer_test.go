@@ -0,0 +1,248 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/protocol"
+	metadataAPI "github.com/segmentio/kafka-go/protocol/metadata"
+	produceAPI "github.com/segmentio/kafka-go/protocol/produce"
+)
+
+// standInBroker is a local stand-in for a Kafka cluster of one broker. It
+// answers the metadata and produce requests of kafka.Writer in process.
+// Produce requests take a fixed round-trip latency, so benchmarks measure
+// round trips rather than a real broker. Metadata is answered at once: the
+// writer looks it up for every message and kafka.Transport caches it.
+type standInBroker struct {
+	latency    time.Duration
+	partitions int
+	// failTopics answer every produce request with this error code.
+	failTopics map[string]kafka.Error
+
+	produceRequests atomic.Int64
+	records         atomic.Int64
+}
+
+func (b *standInBroker) RoundTrip(ctx context.Context, _ net.Addr, req kafka.Request) (kafka.Response, error) {
+	switch req := req.(type) {
+	case *metadataAPI.Request:
+		res := &metadataAPI.Response{
+			Brokers: []metadataAPI.ResponseBroker{{NodeID: 1, Host: "localhost", Port: 9092}},
+		}
+		for _, name := range req.TopicNames {
+			topic := metadataAPI.ResponseTopic{Name: name}
+			for p := 0; p < b.partitions; p++ {
+				topic.Partitions = append(topic.Partitions, metadataAPI.ResponsePartition{
+					PartitionIndex: int32(p), LeaderID: 1, ReplicaNodes: []int32{1}, IsrNodes: []int32{1},
+				})
+			}
+			res.Topics = append(res.Topics, topic)
+		}
+		return res, nil
+
+	case *produceAPI.Request:
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(b.latency):
+		}
+
+		b.produceRequests.Add(1)
+		res := &produceAPI.Response{}
+		for _, t := range req.Topics {
+			topic := produceAPI.ResponseTopic{Topic: t.Topic}
+			for _, p := range t.Partitions {
+				n, err := countRecords(p.RecordSet.Records)
+				if err != nil {
+					return nil, err
+				}
+				b.records.Add(n)
+				topic.Partitions = append(topic.Partitions, produceAPI.ResponsePartition{
+					Partition: p.Partition,
+					ErrorCode: int16(b.failTopics[t.Topic]),
+				})
+			}
+			res.Topics = append(res.Topics, topic)
+		}
+		return res, nil
+	}
+
+	return nil, fmt.Errorf("stand-in broker: unsupported request %T", req)
+}
+
+func countRecords(records protocol.RecordReader) (int64, error) {
+	var n int64
+	for {
+		r, err := records.ReadRecord()
+		if errors.Is(err, io.EOF) {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		if r.Value != nil {
+			r.Value.Close()
+		}
+		n++
+	}
+}
+
+func newStandInProducer(broker *standInBroker) *Producer {
+	p := NewProducer(Config{Brokers: []string{"localhost:9092"}, Topic: "orders-events", RequireAllAcks: true})
+	p.writer.Transport = broker
+	return p
+}
+
+func testMessages(n int, topic string) []Message {
+	msgs := make([]Message, n)
+	for i := range msgs {
+		msgs[i] = Message{
+			Topic:   topic,
+			Key:     []byte(fmt.Sprintf("order-%d", i%16)),
+			Value:   []byte(`{"type":"OrderCreated","payload":{"order_id":"o"}}`),
+			Headers: map[string]string{"event-id": fmt.Sprint(i)},
+		}
+	}
+	return msgs
+}
+
+func TestSendBatch(t *testing.T) {
+	broker := &standInBroker{latency: time.Millisecond, partitions: 4}
+	p := newStandInProducer(broker)
+	defer p.Close()
+
+	results := p.SendBatch(context.Background(), testMessages(50, ""))
+	for i, err := range results {
+		if err != nil {
+			t.Errorf("message %d: %v", i, err)
+		}
+	}
+	if got := broker.records.Load(); got != 50 {
+		t.Errorf("broker received %d records, want 50", got)
+	}
+	// One produce request per partition, not per message.
+	if got := broker.produceRequests.Load(); got > 4 {
+		t.Errorf("%d produce requests for 50 messages on 4 partitions", got)
+	}
+}
+
+func TestSendBatchReportsPerMessageErrors(t *testing.T) {
+	broker := &standInBroker{
+		latency:    time.Millisecond,
+		partitions: 2,
+		failTopics: map[string]kafka.Error{"broken": kafka.MessageSizeTooLarge},
+	}
+	p := newStandInProducer(broker)
+	defer p.Close()
+
+	msgs := append(testMessages(3, "orders-events"), testMessages(2, "broken")...)
+	results := p.SendBatch(context.Background(), msgs)
+
+	for i, err := range results {
+		if wantErr := msgs[i].Topic == "broken"; (err != nil) != wantErr {
+			t.Errorf("message %d (topic %s): err = %v, want error %t", i, msgs[i].Topic, err, wantErr)
+		}
+	}
+}
+
+func TestSendBatchAsync(t *testing.T) {
+	broker := &standInBroker{latency: time.Millisecond, partitions: 4}
+	p := newStandInProducer(broker)
+	defer p.Close()
+
+	var wg sync.WaitGroup
+	var failed atomic.Int64
+	for range 4 {
+		wg.Add(1)
+		p.SendBatchAsync(context.Background(), testMessages(25, ""), func(results []error) {
+			defer wg.Done()
+			for _, err := range results {
+				if err != nil {
+					failed.Add(1)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	if failed.Load() != 0 || broker.records.Load() != 100 {
+		t.Errorf("%d failed, broker received %d records, want 0 and 100", failed.Load(), broker.records.Load())
+	}
+}
+
+const benchBatchSize = 100
+
+// BenchmarkSendMessage writes an outbox batch one message per write, the way
+// the relay did before SendBatch.
+func BenchmarkSendMessage(b *testing.B) {
+	p := newStandInProducer(&standInBroker{latency: time.Millisecond, partitions: 4})
+	defer p.Close()
+	msgs := testMessages(benchBatchSize, "")
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, m := range msgs {
+			if err := p.SendBatch(ctx, []Message{m})[0]; err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.ReportMetric(float64(b.N*benchBatchSize)/b.Elapsed().Seconds(), "msgs/s")
+}
+
+// BenchmarkSendBatch writes the same batch in one WriteMessages call.
+func BenchmarkSendBatch(b *testing.B) {
+	p := newStandInProducer(&standInBroker{latency: time.Millisecond, partitions: 4})
+	defer p.Close()
+	msgs := testMessages(benchBatchSize, "")
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, err := range p.SendBatch(ctx, msgs) {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.ReportMetric(float64(b.N*benchBatchSize)/b.Elapsed().Seconds(), "msgs/s")
+}
+
+// BenchmarkSendBatchAsync keeps four batches in flight, like a poller with
+// MaxInFlight 4.
+func BenchmarkSendBatchAsync(b *testing.B) {
+	p := newStandInProducer(&standInBroker{latency: time.Millisecond, partitions: 4})
+	defer p.Close()
+	msgs := testMessages(benchBatchSize, "")
+	ctx := context.Background()
+
+	inFlight := make(chan struct{}, 4)
+	var wg sync.WaitGroup
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		inFlight <- struct{}{}
+		wg.Add(1)
+		p.SendBatchAsync(ctx, msgs, func(results []error) {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+			for _, err := range results {
+				if err != nil {
+					b.Error(err)
+				}
+			}
+		})
+	}
+	wg.Wait()
+	b.ReportMetric(float64(b.N*benchBatchSize)/b.Elapsed().Seconds(), "msgs/s")
+}
@@ -0,0 +1,44 @@
+package kafka
+
+import "sort"
+
+// Router maps event types to topics. Every event of an order goes to the
+// default topic, keyed by the order id, so a consumer sees the events of one
+// order in the order they were published; services skip the types they do not
+// handle by the event-type header. Explicit routes move a type to its own
+// topic and out of that order: route only types whose consumers read nothing
+// else. The zero value routes nothing; use NewRouter.
+type Router struct {
+	topic  string
+	routes map[string]string
+}
+
+func NewRouter(topic string, routes map[string]string) Router {
+	return Router{
+		topic:  topic,
+		routes: routes,
+	}
+}
+
+// Topic returns the topic events of eventType are published to.
+func (r Router) Topic(eventType string) string {
+	if t, ok := r.routes[eventType]; ok && t != "" {
+		return t
+	}
+	return r.topic
+}
+
+// Topics returns the distinct topics carrying the given event types, sorted.
+func (r Router) Topics(eventTypes []string) []string {
+	seen := make(map[string]bool)
+	var topics []string
+	for _, et := range eventTypes {
+		t := r.Topic(et)
+		if !seen[t] {
+			seen[t] = true
+			topics = append(topics, t)
+		}
+	}
+	sort.Strings(topics)
+	return topics
+}
@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"project/internal/domain/deadletter"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const deadLetterColumns = `
+	id, consumer, topic, kafka_partition, kafka_offset,
+	COALESCE(message_key, ''::bytea), payload, content_type,
+	COALESCE(event_id, ''), COALESCE(event_type, ''), COALESCE(correlation_id, ''),
+	error, attempts, status, created_at, updated_at
+`
+
+type DeadLetterRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewDeadLetterRepository(pool *pgxpool.Pool) *DeadLetterRepository {
+	return &DeadLetterRepository{pool: pool}
+}
+
+// Create stores a dead letter. Re-delivering the same Kafka position for the
+// same consumer refreshes the error and attempt count instead of duplicating it.
+func (r *DeadLetterRepository) Create(ctx context.Context, m *deadletter.Message) error {
+	const sql = `
+		INSERT INTO dead_letters (
+			id, consumer, topic, kafka_partition, kafka_offset,
+			message_key, payload, content_type, event_id, event_type, correlation_id,
+			error, attempts, status, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $15)
+		ON CONFLICT (consumer, topic, kafka_partition, kafka_offset) DO UPDATE
+		SET error = EXCLUDED.error, attempts = EXCLUDED.attempts, updated_at = NOW()
+	`
+
+	_, err := r.pool.Exec(ctx, sql,
+		m.ID, m.Consumer, m.Topic, m.Partition, m.Offset,
+		m.Key, m.Payload, nullIfEmptyDefault(m.ContentType, "application/json"), nullIfEmpty(m.EventID), nullIfEmpty(m.EventType), nullIfEmpty(m.CorrelationID),
+		m.Error, m.Attempts, nullIfEmptyDefault(m.Status, deadletter.StatusNew), m.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert dead letter: %w", err)
+	}
+	return nil
+}
+
+// List returns dead letters matching f, newest first.
+func (r *DeadLetterRepository) List(ctx context.Context, f deadletter.Filter) ([]*deadletter.Message, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.Consumer != "" {
+		add("consumer = $%d", f.Consumer)
+	}
+	if f.EventType != "" {
+		add("event_type = $%d", f.EventType)
+	}
+	if f.CorrelationID != "" {
+		add("correlation_id = $%d", f.CorrelationID)
+	}
+	if f.Status != "" {
+		add("status = $%d", f.Status)
+	}
+	if !f.From.IsZero() {
+		add("created_at >= $%d", f.From)
+	}
+	if !f.To.IsZero() {
+		add("created_at < $%d", f.To)
+	}
+
+	limit := f.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	sql := "SELECT " + deadLetterColumns + " FROM dead_letters"
+	if len(conds) > 0 {
+		sql += " WHERE " + strings.Join(conds, " AND ")
+	}
+	args = append(args, limit)
+	sql += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d", len(args))
+
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*deadletter.Message
+	for rows.Next() {
+		m, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+
+	return messages, rows.Err()
+}
+
+func (r *DeadLetterRepository) GetByID(ctx context.Context, id string) (*deadletter.Message, error) {
+	sql := "SELECT " + deadLetterColumns + " FROM dead_letters WHERE id = $1"
+
+	m, err := scanDeadLetter(r.pool.QueryRow(ctx, sql, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, deadletter.ErrNotFound
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+func (r *DeadLetterRepository) UpdateStatus(ctx context.Context, id string, status string) error {
+	const sql = `
+		UPDATE dead_letters
+		SET status = $2, updated_at = NOW()
+		WHERE id = $1
+	`
+	tag, err := r.pool.Exec(ctx, sql, id, status)
+	if err != nil {
+		return fmt.Errorf("update dead letter status: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return deadletter.ErrNotFound
+	}
+	return nil
+}
+
+func scanDeadLetter(row pgx.Row) (*deadletter.Message, error) {
+	m := &deadletter.Message{}
+	err := row.Scan(
+		&m.ID, &m.Consumer, &m.Topic, &m.Partition, &m.Offset,
+		&m.Key, &m.Payload, &m.ContentType,
+		&m.EventID, &m.EventType, &m.CorrelationID,
+		&m.Error, &m.Attempts, &m.Status, &m.CreatedAt, &m.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("scan dead letter: %w", err)
+	}
+	return m, nil
+}
@@ -34,6 +34,32 @@ func (r *InboxRepository) SaveIfNotExists(ctx context.Context, tx pgx.Tx, consum
 	return tag.RowsAffected() > 0, nil
 }
 
+// HasProcessed reports whether consumer has already processed an event of eventType
+// for correlationID. It reads inside the transaction from ctx, if any.
+func (r *InboxRepository) HasProcessed(ctx context.Context, consumer string, correlationID string, eventType string) (bool, error) {
+	const query = `
+		SELECT EXISTS (
+			SELECT 1
+			FROM inbox_events
+			WHERE consumer = $1 AND correlation_id = $2 AND event_type = $3
+		)
+	`
+
+	var querier interface {
+		QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	} = r.pool
+
+	if tx := GetTx(ctx); tx != nil {
+		querier = tx
+	}
+
+	var exists bool
+	if err := querier.QueryRow(ctx, query, consumer, nullIfEmptyText(correlationID), eventType).Scan(&exists); err != nil {
+		return false, fmt.Errorf("check inbox event: %w", err)
+	}
+	return exists, nil
+}
+
 func (r *InboxRepository) ListByCorrelationID(ctx context.Context, correlationID string) ([]*inbox.Event, error) {
 	const query = `
 		SELECT consumer, event_id, event_type, correlation_id, processed_at
@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LedgerRepository keeps the payment service's per-user balance ledger.
+type LedgerRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLedgerRepository(pool *pgxpool.Pool) *LedgerRepository {
+	return &LedgerRepository{pool: pool}
+}
+
+func (r *LedgerRepository) Balance(ctx context.Context, userID string) (float64, error) {
+	const sql = `
+		SELECT COALESCE(SUM(amount), 0)::float8
+		FROM payment_ledger
+		WHERE user_id = $1
+	`
+
+	var querier interface {
+		QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	} = r.pool
+
+	if tx := GetTx(ctx); tx != nil {
+		querier = tx
+	}
+
+	var balance float64
+	if err := querier.QueryRow(ctx, sql, userID).Scan(&balance); err != nil {
+		return 0, fmt.Errorf("get balance: %w", err)
+	}
+	return balance, nil
+}
+
+// LockUser takes a per-user advisory lock for the rest of the transaction in
+// ctx, so that the balance check and the hold of one authorization are not
+// interleaved with another authorization of the same user.
+func (r *LedgerRepository) LockUser(ctx context.Context, userID string) error {
+	tx := GetTx(ctx)
+	if tx == nil {
+		return errors.New("lock ledger user: no transaction in context")
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('payment_ledger:' || $1::text))`, userID); err != nil {
+		return fmt.Errorf("lock ledger user: %w", err)
+	}
+	return nil
+}
+
+// Hold reserves amount for orderID. Holding the same order twice is a no-op.
+func (r *LedgerRepository) Hold(ctx context.Context, userID string, orderID string, amount float64) error {
+	const sql = `
+		INSERT INTO payment_ledger (user_id, order_id, kind, amount)
+		VALUES ($1, $2, 'HOLD', -$3::numeric)
+		ON CONFLICT (order_id, kind) WHERE order_id IS NOT NULL DO NOTHING
+	`
+
+	var executor interface {
+		Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	} = r.pool
+
+	if tx := GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	if _, err := executor.Exec(ctx, sql, userID, orderID, amount); err != nil {
+		return fmt.Errorf("insert ledger hold: %w", err)
+	}
+	return nil
+}
+
+// Release returns the funds held for orderID. Releasing twice is a no-op.
+func (r *LedgerRepository) Release(ctx context.Context, orderID string) error {
+	const sql = `
+		INSERT INTO payment_ledger (user_id, order_id, kind, amount)
+		SELECT user_id, order_id, 'RELEASE', -amount
+		FROM payment_ledger
+		WHERE order_id = $1 AND kind = 'HOLD'
+		ON CONFLICT (order_id, kind) WHERE order_id IS NOT NULL DO NOTHING
+	`
+
+	var executor interface {
+		Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	} = r.pool
+
+	if tx := GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	if _, err := executor.Exec(ctx, sql, orderID); err != nil {
+		return fmt.Errorf("insert ledger release: %w", err)
+	}
+	return nil
+}
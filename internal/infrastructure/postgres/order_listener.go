@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// OrderChannel is the LISTEN/NOTIFY channel signalled with the order id for
+// every status change, inbox and outbox transition of an order (migration 019).
+const OrderChannel = "order_changes"
+
+// OrderListener LISTENs on OrderChannel over a dedicated connection and fans
+// the notifications out to the subscribers of each order.
+type OrderListener struct {
+	cfg        Config
+	retryDelay time.Duration
+
+	mu   sync.Mutex
+	subs map[string]map[chan struct{}]struct{}
+}
+
+func NewOrderListener(cfg Config) *OrderListener {
+	return &OrderListener{
+		cfg:        cfg,
+		retryDelay: 2 * time.Second,
+		subs:       make(map[string]map[chan struct{}]struct{}),
+	}
+}
+
+// Subscribe returns a channel woken up after every change of orderID, and a
+// func that releases it. Wake-ups are coalesced: a pending one is not
+// duplicated.
+func (l *OrderListener) Subscribe(orderID string) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	l.mu.Lock()
+	if l.subs[orderID] == nil {
+		l.subs[orderID] = make(map[chan struct{}]struct{})
+	}
+	l.subs[orderID][ch] = struct{}{}
+	l.mu.Unlock()
+
+	return ch, func() {
+		l.mu.Lock()
+		delete(l.subs[orderID], ch)
+		if len(l.subs[orderID]) == 0 {
+			delete(l.subs, orderID)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// Run dispatches notifications until ctx is done. The connection is
+// re-established after errors; every subscriber is woken up then, since
+// notifications sent in between are lost.
+func (l *OrderListener) Run(ctx context.Context) {
+	for {
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("order listener stopped: %v; reconnecting in %s", err, l.retryDelay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(l.retryDelay):
+		}
+		l.wakeAll()
+	}
+}
+
+func (l *OrderListener) listen(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, l.cfg.DSN())
+	if err != nil {
+		return fmt.Errorf("connect listener: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{OrderChannel}.Sanitize()); err != nil {
+		return fmt.Errorf("listen %s: %w", OrderChannel, err)
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("wait for notification: %w", err)
+		}
+		l.wake(n.Payload)
+	}
+}
+
+func (l *OrderListener) wake(orderID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for ch := range l.subs[orderID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (l *OrderListener) wakeAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, subs := range l.subs {
+		for ch := range subs {
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
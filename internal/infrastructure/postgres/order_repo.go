@@ -2,9 +2,12 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"project/internal/domain/order"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -22,12 +25,12 @@ func (r *OrderRepository) Create(ctx context.Context, o *order.Order) error {
 		INSERT INTO orders (
 			id, user_id, status, total_amount,
 			from_city, to_city, travel_date, travel_time, airline,
-			created_at, updated_at
+			saga_mode, created_at, updated_at
 		)
 		VALUES (
 			$1, $2, $3, $4,
 			$5, $6, NULLIF($7, '')::date, $8, $9,
-			$10, $11
+			$10, $11, $12
 		)
 	`
 
@@ -43,7 +46,7 @@ func (r *OrderRepository) Create(ctx context.Context, o *order.Order) error {
 	_, err := executor.Exec(ctx, sql,
 		o.ID, o.UserID, o.Status, o.TotalAmount,
 		nullIfEmptyText(o.FromCity), nullIfEmptyText(o.ToCity), o.TravelDate, nullIfEmptyText(o.TravelTime), nullIfEmptyText(o.Airline),
-		o.CreatedAt, o.UpdatedAt)
+		o.SagaMode, o.CreatedAt, o.UpdatedAt)
 
 	if err != nil {
 		return fmt.Errorf("insert order: %w", err)
@@ -52,33 +55,132 @@ func (r *OrderRepository) Create(ctx context.Context, o *order.Order) error {
 	return nil
 }
 
-func (r *OrderRepository) UpdateStatus(ctx context.Context, id string, status string) error {
+// UpdateStatus moves the order to change.To if the state machine allows it from the
+// current status, and records the change in order_status_history. The check, the
+// update and the history row are a single compare-and-set statement, so concurrent
+// writers cannot skip a step. It returns order.ErrNotFound or an
+// *order.TransitionError (matching order.ErrInvalidTransition).
+func (r *OrderRepository) UpdateStatus(ctx context.Context, id string, change order.StatusChange) error {
 	const sql = `
-		UPDATE orders
-		SET status = $2, updated_at = NOW()
-		WHERE id = $1
+		WITH prev AS (
+			SELECT id, status
+			FROM orders
+			WHERE id = $1
+			FOR UPDATE
+		), updated AS (
+			UPDATE orders o
+			SET status = $2, updated_at = NOW()
+			FROM prev
+			WHERE o.id = prev.id AND prev.status = ANY($3)
+			RETURNING prev.status AS from_status
+		)
+		INSERT INTO order_status_history (order_id, from_status, to_status, changed_by, event_type, causation_id, changed_at)
+		SELECT $1, from_status, $2, $4, $5, $6, NOW()
+		FROM updated
 	`
 
 	var executor interface {
 		Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+		QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 	} = r.pool
 
 	if tx := GetTx(ctx); tx != nil {
 		executor = tx
 	}
 
-	cmdTag, err := executor.Exec(ctx, sql, id, status)
+	cmdTag, err := executor.Exec(ctx, sql, id, change.To, order.SourcesOf(change.To),
+		nullIfEmptyDefault(change.ChangedBy, "unknown"), nullIfEmpty(change.EventType), nullIfEmpty(change.CausationID))
 	if err != nil {
 		return fmt.Errorf("update order status: %w", err)
 	}
 
 	if cmdTag.RowsAffected() == 0 {
-		return fmt.Errorf("order not found")
+		var current string
+		err := executor.QueryRow(ctx, `SELECT status FROM orders WHERE id = $1`, id).Scan(&current)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return order.ErrNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("get order status: %w", err)
+		}
+		return &order.TransitionError{OrderID: id, From: current, To: change.To}
 	}
 
 	return nil
 }
 
+// AddInitialHistory records the status a new order was created with.
+func (r *OrderRepository) AddInitialHistory(ctx context.Context, o *order.Order, changedBy string) error {
+	const sql = `
+		INSERT INTO order_status_history (order_id, from_status, to_status, changed_by, changed_at)
+		VALUES ($1, NULL, $2, $3, $4)
+	`
+
+	var executor interface {
+		Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	} = r.pool
+
+	if tx := GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	if _, err := executor.Exec(ctx, sql, o.ID, o.Status, changedBy, o.CreatedAt); err != nil {
+		return fmt.Errorf("insert order status history: %w", err)
+	}
+	return nil
+}
+
+// ListHistory returns the status changes of an order, oldest first.
+func (r *OrderRepository) ListHistory(ctx context.Context, id string) ([]*order.HistoryEntry, error) {
+	const sql = `
+		SELECT
+			id, order_id,
+			COALESCE(from_status, ''), to_status, changed_by,
+			COALESCE(event_type, ''), COALESCE(causation_id::text, ''),
+			changed_at
+		FROM order_status_history
+		WHERE order_id = $1
+		ORDER BY changed_at ASC, id ASC
+	`
+
+	rows, err := r.pool.Query(ctx, sql, id)
+	if err != nil {
+		return nil, fmt.Errorf("query order status history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*order.HistoryEntry
+	for rows.Next() {
+		e := &order.HistoryEntry{}
+		if err := rows.Scan(&e.ID, &e.OrderID, &e.FromStatus, &e.ToStatus, &e.ChangedBy, &e.EventType, &e.CausationID, &e.ChangedAt); err != nil {
+			return nil, fmt.Errorf("scan order status history: %w", err)
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+// Lock takes the row lock of the order for the rest of the transaction in
+// ctx, so that handlers deciding on the same order run one after the other.
+// It returns order.ErrNotFound for unknown orders.
+func (r *OrderRepository) Lock(ctx context.Context, id string) error {
+	tx := GetTx(ctx)
+	if tx == nil {
+		return errors.New("lock order: no transaction in context")
+	}
+
+	var locked string
+	err := tx.QueryRow(ctx, `SELECT id FROM orders WHERE id = $1 FOR UPDATE`, id).Scan(&locked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return order.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("lock order: %w", err)
+	}
+	return nil
+}
+
 func (r *OrderRepository) GetByID(ctx context.Context, id string) (*order.Order, error) {
 	const sql = `
 		SELECT
@@ -88,7 +190,7 @@ func (r *OrderRepository) GetByID(ctx context.Context, id string) (*order.Order,
 			COALESCE(to_char(travel_date, 'YYYY-MM-DD'), ''),
 			COALESCE(travel_time, ''),
 			COALESCE(airline, ''),
-			created_at, updated_at
+			saga_mode, created_at, updated_at
 		FROM orders
 		WHERE id = $1
 	`
@@ -97,15 +199,47 @@ func (r *OrderRepository) GetByID(ctx context.Context, id string) (*order.Order,
 	err := r.pool.QueryRow(ctx, sql, id).Scan(
 		&o.ID, &o.UserID, &o.Status, &o.TotalAmount,
 		&o.FromCity, &o.ToCity, &o.TravelDate, &o.TravelTime, &o.Airline,
-		&o.CreatedAt, &o.UpdatedAt,
+		&o.SagaMode, &o.CreatedAt, &o.UpdatedAt,
 	)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, order.ErrNotFound
+		}
 		return nil, fmt.Errorf("get order by id: %w", err)
 	}
 
 	return &o, nil
 }
 
+// ListStuck returns choreographed orders that have been in status for longer than
+// olderThan, oldest first. Deadlines of orchestrated orders are kept by the orchestrator.
+func (r *OrderRepository) ListStuck(ctx context.Context, status string, olderThan time.Duration, limit int) ([]*order.Order, error) {
+	const sql = `
+		SELECT id, user_id, status, total_amount, saga_mode, created_at, updated_at
+		FROM orders
+		WHERE status = $1 AND saga_mode = 'choreography' AND updated_at < NOW() - make_interval(secs => $2)
+		ORDER BY updated_at ASC
+		LIMIT $3
+	`
+
+	rows, err := r.pool.Query(ctx, sql, status, olderThan.Seconds(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("query stuck orders: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []*order.Order
+	for rows.Next() {
+		o := &order.Order{}
+		if err := rows.Scan(&o.ID, &o.UserID, &o.Status, &o.TotalAmount, &o.SagaMode, &o.CreatedAt, &o.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan stuck order: %w", err)
+		}
+		orders = append(orders, o)
+	}
+
+	return orders, rows.Err()
+}
+
 func nullIfEmptyText(s string) any {
 	if s == "" {
 		return nil
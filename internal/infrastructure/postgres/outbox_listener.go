@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// OutboxListener LISTENs on OutboxChannel over a dedicated connection and
+// signals the poller that new rows were committed.
+type OutboxListener struct {
+	cfg        Config
+	retryDelay time.Duration
+}
+
+func NewOutboxListener(cfg Config) *OutboxListener {
+	return &OutboxListener{cfg: cfg, retryDelay: 2 * time.Second}
+}
+
+// Run delivers a wake-up on notify for every notification until ctx is done.
+// Wake-ups are coalesced: a pending one is not duplicated. The connection is
+// re-established after errors.
+func (l *OutboxListener) Run(ctx context.Context, notify chan<- struct{}) {
+	for {
+		err := l.listen(ctx, notify)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("outbox listener stopped: %v; reconnecting in %s", err, l.retryDelay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(l.retryDelay):
+		}
+	}
+}
+
+func (l *OutboxListener) listen(ctx context.Context, notify chan<- struct{}) error {
+	conn, err := pgx.Connect(ctx, l.cfg.DSN())
+	if err != nil {
+		return fmt.Errorf("connect listener: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{OutboxChannel}.Sanitize()); err != nil {
+		return fmt.Errorf("listen %s: %w", OutboxChannel, err)
+	}
+
+	for {
+		if _, err := conn.WaitForNotification(ctx); err != nil {
+			return fmt.Errorf("wait for notification: %w", err)
+		}
+		select {
+		case notify <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OutboxPartitionRepository manages relay membership and partition leases.
+type OutboxPartitionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewOutboxPartitionRepository(pool *pgxpool.Pool) *OutboxPartitionRepository {
+	return &OutboxPartitionRepository{pool: pool}
+}
+
+// Heartbeat records workerID as a live relay member.
+func (r *OutboxPartitionRepository) Heartbeat(ctx context.Context, workerID string) error {
+	const sql = `
+		INSERT INTO outbox_relay_members (worker_id, heartbeat_at)
+		VALUES ($1, NOW())
+		ON CONFLICT (worker_id) DO UPDATE SET heartbeat_at = NOW()
+	`
+	if _, err := r.pool.Exec(ctx, sql, workerID); err != nil {
+		return fmt.Errorf("relay heartbeat: %w", err)
+	}
+	return nil
+}
+
+// LiveMembers counts members whose last heartbeat is younger than ttl.
+func (r *OutboxPartitionRepository) LiveMembers(ctx context.Context, ttl time.Duration) (int, error) {
+	const sql = `
+		SELECT COUNT(*)
+		FROM outbox_relay_members
+		WHERE heartbeat_at > NOW() - make_interval(secs => $1)
+	`
+	var n int
+	if err := r.pool.QueryRow(ctx, sql, ttl.Seconds()).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count relay members: %w", err)
+	}
+	return n, nil
+}
+
+// Renew extends the leases workerID still holds and returns those partitions.
+func (r *OutboxPartitionRepository) Renew(ctx context.Context, workerID string, lease time.Duration) ([]int, error) {
+	const sql = `
+		UPDATE outbox_partitions
+		SET lease_expires_at = NOW() + make_interval(secs => $2)
+		WHERE owner = $1 AND lease_expires_at > NOW()
+		RETURNING partition
+	`
+	return r.queryPartitions(ctx, "renew partition leases", sql, workerID, lease.Seconds())
+}
+
+// Claim leases up to limit partitions that are free or whose lease expired.
+func (r *OutboxPartitionRepository) Claim(ctx context.Context, workerID string, limit int, lease time.Duration) ([]int, error) {
+	const sql = `
+		WITH free AS (
+			SELECT partition
+			FROM outbox_partitions
+			WHERE owner IS NULL OR lease_expires_at IS NULL OR lease_expires_at <= NOW()
+			ORDER BY partition
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE outbox_partitions p
+		SET owner = $1, lease_expires_at = NOW() + make_interval(secs => $3)
+		FROM free
+		WHERE p.partition = free.partition
+		RETURNING p.partition
+	`
+	return r.queryPartitions(ctx, "claim partitions", sql, workerID, limit, lease.Seconds())
+}
+
+// Release gives up the listed partitions held by workerID.
+func (r *OutboxPartitionRepository) Release(ctx context.Context, workerID string, partitions []int) error {
+	const sql = `
+		UPDATE outbox_partitions
+		SET owner = NULL, lease_expires_at = NULL
+		WHERE owner = $1 AND partition = ANY($2)
+	`
+	if _, err := r.pool.Exec(ctx, sql, workerID, partitions); err != nil {
+		return fmt.Errorf("release partitions: %w", err)
+	}
+	return nil
+}
+
+// Leave releases every partition of workerID and removes it from the members,
+// so the others can take over without waiting for the leases to expire.
+func (r *OutboxPartitionRepository) Leave(ctx context.Context, workerID string) error {
+	if _, err := r.pool.Exec(ctx, `UPDATE outbox_partitions SET owner = NULL, lease_expires_at = NULL WHERE owner = $1`, workerID); err != nil {
+		return fmt.Errorf("release partitions: %w", err)
+	}
+	if _, err := r.pool.Exec(ctx, `DELETE FROM outbox_relay_members WHERE worker_id = $1`, workerID); err != nil {
+		return fmt.Errorf("remove relay member: %w", err)
+	}
+	return nil
+}
+
+func (r *OutboxPartitionRepository) queryPartitions(ctx context.Context, op string, sql string, args ...any) ([]int, error) {
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var partitions []int
+	for rows.Next() {
+		var p int
+		if err := rows.Scan(&p); err != nil {
+			return nil, fmt.Errorf("scan partition: %w", err)
+		}
+		partitions = append(partitions, p)
+	}
+	return partitions, rows.Err()
+}
@@ -3,12 +3,17 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"project/internal/domain/event"
 	"project/internal/domain/outbox"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// OutboxChannel is the LISTEN/NOTIFY channel signalled for every new outbox row.
+const OutboxChannel = "outbox_events"
+
 type OutboxRepository struct {
 	pool *pgxpool.Pool
 }
@@ -19,10 +24,14 @@ func NewOutboxRepository(pool *pgxpool.Pool) *OutboxRepository {
 
 func (r *OutboxRepository) Create(ctx context.Context, e *outbox.Event) error {
 	const sql = `
-		INSERT INTO outbox (id, event_type, payload, status, correlation_id, causation_id, producer, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		INSERT INTO outbox (id, event_type, payload, status, correlation_id, causation_id, producer, schema_version, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
 	`
 
+	if e.SchemaVersion == 0 {
+		e.SchemaVersion = event.Version(e.EventType)
+	}
+
 	var executor interface {
 		Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
 	} = r.pool
@@ -32,27 +41,57 @@ func (r *OutboxRepository) Create(ctx context.Context, e *outbox.Event) error {
 	}
 
 	_, err := executor.Exec(ctx, sql,
-		e.ID, e.EventType, e.Payload, e.Status, nullIfEmpty(e.CorrelationID), nullIfEmpty(e.CausationID), nullIfEmptyDefault(e.Producer, "unknown"), e.CreatedAt)
+		e.ID, e.EventType, e.Payload, e.Status, nullIfEmpty(e.CorrelationID), nullIfEmpty(e.CausationID), nullIfEmptyDefault(e.Producer, "unknown"), e.SchemaVersion, e.CreatedAt)
 
 	if err != nil {
 		return fmt.Errorf("insert outbox event: %w", err)
 	}
 
+	// Delivered on commit only, so the poller never wakes up for a row it cannot see yet.
+	if _, err := executor.Exec(ctx, `SELECT pg_notify($1, $2)`, OutboxChannel, e.ID); err != nil {
+		return fmt.Errorf("notify outbox event: %w", err)
+	}
+
 	return nil
 }
 
-func (r *OutboxRepository) FetchBatch(ctx context.Context, limit int) ([]*outbox.Event, error) {
+// FetchBatch claims up to limit 'new' events for workerID. Claimed rows are
+// moved to 'processing' with a lease; if the worker dies before marking them,
+// ReclaimExpired returns them to 'new' once the lease runs out.
+//
+// Only the head of each aggregate (the first event of a correlation_id by seq,
+// i.e. write order, not yet processed) is claimed, and only while no earlier
+// event of it is in flight, so events of one order reach Kafka in the order
+// they were written and a batch holds at most one event per aggregate.
+//
+// partitions restricts the claim to the partitions leased by the worker; nil
+// means all of them.
+func (r *OutboxRepository) FetchBatch(ctx context.Context, workerID string, partitions []int, limit int, lease time.Duration) ([]*outbox.Event, error) {
 	const sql = `
-		WITH claimed_events AS (
-			SELECT id
+		WITH heads AS (
+			SELECT DISTINCT ON (COALESCE(correlation_id::text, id::text)) id, status
 			FROM outbox
-			WHERE status = 'new'
-			ORDER BY created_at ASC
+			WHERE status IN ('new', 'processing')
+			  AND ($4::int[] IS NULL OR partition_key = ANY($4))
+			ORDER BY COALESCE(correlation_id::text, id::text), seq ASC
+		),
+		claimed_events AS (
+			SELECT o.id
+			FROM outbox o
+			JOIN heads h ON h.id = o.id
+			WHERE h.status = 'new'
+			  -- re-checked on the locked row version: a head another worker
+			  -- claimed after the heads snapshot is skipped, not claimed again
+			  AND o.status = 'new'
+			ORDER BY o.seq ASC
 			LIMIT $1
-			FOR UPDATE SKIP LOCKED
+			FOR UPDATE OF o SKIP LOCKED
 		)
 		UPDATE outbox
-		SET status = 'processing', updated_at = NOW()
+		SET status = 'processing',
+			claimed_by = $2,
+			lease_expires_at = NOW() + make_interval(secs => $3),
+			updated_at = NOW()
 		WHERE id IN (SELECT id FROM claimed_events)
 		RETURNING
 			id,
@@ -62,11 +101,14 @@ func (r *OutboxRepository) FetchBatch(ctx context.Context, limit int) ([]*outbox
 			COALESCE(correlation_id::text, ''),
 			COALESCE(causation_id::text, ''),
 			COALESCE(producer, 'unknown'),
+			schema_version,
+			COALESCE(claimed_by, ''),
+			lease_expires_at,
 			created_at,
 			updated_at
 	`
 
-	rows, err := r.pool.Query(ctx, sql, limit)
+	rows, err := r.pool.Query(ctx, sql, limit, workerID, lease.Seconds(), partitions)
 	if err != nil {
 		return nil, fmt.Errorf("query outbox: %w", err)
 	}
@@ -75,7 +117,7 @@ func (r *OutboxRepository) FetchBatch(ctx context.Context, limit int) ([]*outbox
 	var events []*outbox.Event
 	for rows.Next() {
 		e := &outbox.Event{}
-		if err := rows.Scan(&e.ID, &e.EventType, &e.Payload, &e.Status, &e.CorrelationID, &e.CausationID, &e.Producer, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		if err := rows.Scan(&e.ID, &e.EventType, &e.Payload, &e.Status, &e.CorrelationID, &e.CausationID, &e.Producer, &e.SchemaVersion, &e.ClaimedBy, &e.LeaseExpiresAt, &e.CreatedAt, &e.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scan event: %w", err)
 		}
 		events = append(events, e)
@@ -84,30 +126,95 @@ func (r *OutboxRepository) FetchBatch(ctx context.Context, limit int) ([]*outbox
 	return events, nil
 }
 
-func (r *OutboxRepository) MarkProcessed(ctx context.Context, ids []string) error {
+// MarkProcessed marks the events workerID still holds as 'processed' and
+// returns how many it marked. An event whose lease expired and was reclaimed
+// (and possibly claimed by another worker) is left alone: its new holder
+// publishes and marks it.
+func (r *OutboxRepository) MarkProcessed(ctx context.Context, workerID string, ids []string) (int64, error) {
+	const sql = `
+		UPDATE outbox
+		SET status = 'processed', claimed_by = NULL, lease_expires_at = NULL, updated_at = NOW()
+		WHERE id = ANY($1) AND claimed_by = $2 AND status = 'processing'
+	`
+	tag, err := r.pool.Exec(ctx, sql, ids, workerID)
+	if err != nil {
+		return 0, fmt.Errorf("mark processed: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// MarkFailed returns the events workerID still holds to 'new' for a retry and
+// returns how many it released. Like MarkProcessed, it does not touch events
+// that lost their lease.
+func (r *OutboxRepository) MarkFailed(ctx context.Context, workerID string, ids []string) (int64, error) {
+	const sql = `
+		UPDATE outbox
+		SET status = 'new', claimed_by = NULL, lease_expires_at = NULL, updated_at = NOW()
+		WHERE id = ANY($1) AND claimed_by = $2 AND status = 'processing'
+	`
+	tag, err := r.pool.Exec(ctx, sql, ids, workerID)
+	if err != nil {
+		return 0, fmt.Errorf("mark failed: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// MarkStreamed marks events the cdc relay published straight from the
+// replication stream, which are never claimed, as 'processed'. Events a poller
+// or catch-up claimed in the meantime are left to their holder.
+func (r *OutboxRepository) MarkStreamed(ctx context.Context, ids []string) (int64, error) {
 	const sql = `
 		UPDATE outbox
 		SET status = 'processed', updated_at = NOW()
-		WHERE id = ANY($1)
+		WHERE id = ANY($1) AND status = 'new'
 	`
-	_, err := r.pool.Exec(ctx, sql, ids)
+	tag, err := r.pool.Exec(ctx, sql, ids)
 	if err != nil {
-		return fmt.Errorf("mark processed: %w", err)
+		return 0, fmt.Errorf("mark streamed: %w", err)
 	}
-	return nil
+	return tag.RowsAffected(), nil
 }
 
-func (r *OutboxRepository) MarkFailed(ctx context.Context, ids []string) error {
+// FilterUnprocessed returns the ids that are not yet marked 'processed'. The cdc
+// relay uses it to skip events it already published before a restart.
+func (r *OutboxRepository) FilterUnprocessed(ctx context.Context, ids []string) ([]string, error) {
+	const sql = `
+		SELECT id::text
+		FROM outbox
+		WHERE id = ANY($1) AND status <> 'processed'
+	`
+	rows, err := r.pool.Query(ctx, sql, ids)
+	if err != nil {
+		return nil, fmt.Errorf("query unprocessed outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var pending []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan outbox id: %w", err)
+		}
+		pending = append(pending, id)
+	}
+
+	return pending, rows.Err()
+}
+
+// ReclaimExpired returns 'processing' events whose lease has expired back to 'new'.
+// Rows claimed before leases existed have no deadline and are reclaimed as well.
+func (r *OutboxRepository) ReclaimExpired(ctx context.Context) (int64, error) {
 	const sql = `
 		UPDATE outbox
-		SET status = 'new', updated_at = NOW()
-		WHERE id = ANY($1)
+		SET status = 'new', claimed_by = NULL, lease_expires_at = NULL, updated_at = NOW()
+		WHERE status = 'processing'
+		  AND (lease_expires_at IS NULL OR lease_expires_at < NOW())
 	`
-	_, err := r.pool.Exec(ctx, sql, ids)
+	tag, err := r.pool.Exec(ctx, sql)
 	if err != nil {
-		return fmt.Errorf("mark failed: %w", err)
+		return 0, fmt.Errorf("reclaim expired leases: %w", err)
 	}
-	return nil
+	return tag.RowsAffected(), nil
 }
 
 func (r *OutboxRepository) ListByCorrelationID(ctx context.Context, correlationID string) ([]*outbox.Event, error) {
@@ -120,11 +227,14 @@ func (r *OutboxRepository) ListByCorrelationID(ctx context.Context, correlationI
 			COALESCE(correlation_id::text, ''),
 			COALESCE(causation_id::text, ''),
 			COALESCE(producer, 'unknown'),
+			schema_version,
+			COALESCE(claimed_by, ''),
+			lease_expires_at,
 			created_at,
 			updated_at
 		FROM outbox
 		WHERE correlation_id = $1
-		ORDER BY created_at ASC
+		ORDER BY seq ASC
 	`
 
 	rows, err := r.pool.Query(ctx, sql, nullIfEmpty(correlationID))
@@ -136,7 +246,7 @@ func (r *OutboxRepository) ListByCorrelationID(ctx context.Context, correlationI
 	var events []*outbox.Event
 	for rows.Next() {
 		e := &outbox.Event{}
-		if err := rows.Scan(&e.ID, &e.EventType, &e.Payload, &e.Status, &e.CorrelationID, &e.CausationID, &e.Producer, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		if err := rows.Scan(&e.ID, &e.EventType, &e.Payload, &e.Status, &e.CorrelationID, &e.CausationID, &e.Producer, &e.SchemaVersion, &e.ClaimedBy, &e.LeaseExpiresAt, &e.CreatedAt, &e.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scan outbox event: %w", err)
 		}
 		events = append(events, e)
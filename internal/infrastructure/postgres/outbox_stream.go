@@ -0,0 +1,268 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"project/internal/domain/outbox"
+
+	"github.com/jackc/pglogrepl"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// OutboxStreamConfig names the logical replication objects the stream reads from.
+type OutboxStreamConfig struct {
+	SlotName    string
+	Publication string
+	// StandbyTimeout is the interval between status updates sent to the server.
+	StandbyTimeout time.Duration
+}
+
+// OutboxTx is one committed transaction that inserted outbox rows.
+type OutboxTx struct {
+	// EndLSN is acknowledged once the events are published, so the slot
+	// never resends the transaction after a restart.
+	EndLSN pglogrepl.LSN
+	Events []*outbox.Event
+}
+
+// OutboxStream decodes outbox inserts from a pgoutput logical replication slot.
+type OutboxStream struct {
+	cfg       Config
+	streamCfg OutboxStreamConfig
+	typeMap   *pgtype.Map
+
+	conn      *pgconn.PgConn
+	relations map[uint32]*pglogrepl.RelationMessage
+
+	// ackedLSN is the position confirmed to the server; pending is set while a
+	// returned transaction has not been acknowledged yet.
+	ackedLSN      pglogrepl.LSN
+	pending       bool
+	nextStatusAt  time.Time
+	replyRequired bool
+}
+
+func NewOutboxStream(cfg Config, streamCfg OutboxStreamConfig) *OutboxStream {
+	if streamCfg.StandbyTimeout <= 0 {
+		streamCfg.StandbyTimeout = 10 * time.Second
+	}
+	return &OutboxStream{
+		cfg:       cfg,
+		streamCfg: streamCfg,
+		typeMap:   pgtype.NewMap(),
+	}
+}
+
+// Connect opens a replication connection, creates the slot if it does not
+// exist yet and starts streaming from the slot's confirmed position.
+func (s *OutboxStream) Connect(ctx context.Context) error {
+	conn, err := pgconn.Connect(ctx, s.cfg.DSN()+"&replication=database")
+	if err != nil {
+		return fmt.Errorf("connect replication: %w", err)
+	}
+
+	_, err = pglogrepl.CreateReplicationSlot(ctx, conn, s.streamCfg.SlotName, "pgoutput",
+		pglogrepl.CreateReplicationSlotOptions{Mode: pglogrepl.LogicalReplication})
+	var pgErr *pgconn.PgError
+	if err != nil && !(errors.As(err, &pgErr) && pgErr.Code == "42710") {
+		conn.Close(ctx)
+		return fmt.Errorf("create replication slot %s: %w", s.streamCfg.SlotName, err)
+	}
+
+	// Starting at 0 resumes from the slot's confirmed_flush_lsn.
+	err = pglogrepl.StartReplication(ctx, conn, s.streamCfg.SlotName, 0, pglogrepl.StartReplicationOptions{
+		Mode: pglogrepl.LogicalReplication,
+		PluginArgs: []string{
+			"proto_version '1'",
+			fmt.Sprintf("publication_names '%s'", s.streamCfg.Publication),
+		},
+	})
+	if err != nil {
+		conn.Close(ctx)
+		return fmt.Errorf("start replication: %w", err)
+	}
+
+	s.conn = conn
+	s.relations = make(map[uint32]*pglogrepl.RelationMessage)
+	s.pending = false
+	s.nextStatusAt = time.Now().Add(s.streamCfg.StandbyTimeout)
+	return nil
+}
+
+// Receive blocks until the next committed transaction with outbox inserts.
+// The previous transaction must be acknowledged with Ack first.
+func (s *OutboxStream) Receive(ctx context.Context) (*OutboxTx, error) {
+	var tx *OutboxTx
+
+	for {
+		if s.replyRequired || time.Now().After(s.nextStatusAt) {
+			if err := s.sendStatus(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		recvCtx, cancel := context.WithDeadline(ctx, s.nextStatusAt)
+		rawMsg, err := s.conn.ReceiveMessage(recvCtx)
+		cancel()
+		if err != nil {
+			if pgconn.Timeout(err) && ctx.Err() == nil {
+				continue
+			}
+			return nil, fmt.Errorf("receive replication message: %w", err)
+		}
+
+		if errMsg, ok := rawMsg.(*pgproto3.ErrorResponse); ok {
+			return nil, fmt.Errorf("replication error: %s", errMsg.Message)
+		}
+		msg, ok := rawMsg.(*pgproto3.CopyData)
+		if !ok || len(msg.Data) == 0 {
+			continue
+		}
+
+		switch msg.Data[0] {
+		case pglogrepl.PrimaryKeepaliveMessageByteID:
+			pkm, err := pglogrepl.ParsePrimaryKeepaliveMessage(msg.Data[1:])
+			if err != nil {
+				return nil, fmt.Errorf("parse keepalive: %w", err)
+			}
+			// Between transactions everything up to ServerWALEnd has been
+			// seen, so the slot may move past WAL of unrelated tables.
+			if tx == nil && !s.pending && pkm.ServerWALEnd > s.ackedLSN {
+				s.ackedLSN = pkm.ServerWALEnd
+			}
+			if pkm.ReplyRequested {
+				s.replyRequired = true
+			}
+
+		case pglogrepl.XLogDataByteID:
+			xld, err := pglogrepl.ParseXLogData(msg.Data[1:])
+			if err != nil {
+				return nil, fmt.Errorf("parse xlog data: %w", err)
+			}
+			logical, err := pglogrepl.Parse(xld.WALData)
+			if err != nil {
+				return nil, fmt.Errorf("parse logical message: %w", err)
+			}
+
+			switch m := logical.(type) {
+			case *pglogrepl.RelationMessage:
+				s.relations[m.RelationID] = m
+			case *pglogrepl.BeginMessage:
+				tx = &OutboxTx{}
+			case *pglogrepl.InsertMessage:
+				if tx == nil {
+					continue
+				}
+				e, err := s.decodeInsert(m)
+				if err != nil {
+					return nil, err
+				}
+				if e != nil {
+					tx.Events = append(tx.Events, e)
+				}
+			case *pglogrepl.CommitMessage:
+				if tx == nil {
+					continue
+				}
+				tx.EndLSN = m.TransactionEndLSN
+				if len(tx.Events) == 0 {
+					s.ackedLSN = m.TransactionEndLSN
+					tx = nil
+					continue
+				}
+				s.pending = true
+				return tx, nil
+			}
+		}
+	}
+}
+
+// Ack confirms that everything up to lsn has been published.
+func (s *OutboxStream) Ack(ctx context.Context, lsn pglogrepl.LSN) error {
+	if lsn > s.ackedLSN {
+		s.ackedLSN = lsn
+	}
+	s.pending = false
+	return s.sendStatus(ctx)
+}
+
+// AckedLSN returns the last position confirmed to the server.
+func (s *OutboxStream) AckedLSN() pglogrepl.LSN {
+	return s.ackedLSN
+}
+
+func (s *OutboxStream) Close(ctx context.Context) error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close(ctx)
+	s.conn = nil
+	return err
+}
+
+func (s *OutboxStream) sendStatus(ctx context.Context) error {
+	err := pglogrepl.SendStandbyStatusUpdate(ctx, s.conn, pglogrepl.StandbyStatusUpdate{
+		WALWritePosition: s.ackedLSN,
+	})
+	if err != nil {
+		return fmt.Errorf("send standby status: %w", err)
+	}
+	s.replyRequired = false
+	s.nextStatusAt = time.Now().Add(s.streamCfg.StandbyTimeout)
+	return nil
+}
+
+// decodeInsert maps a pgoutput tuple of the outbox table to an event.
+// Inserts into other published tables are ignored.
+func (s *OutboxStream) decodeInsert(m *pglogrepl.InsertMessage) (*outbox.Event, error) {
+	rel, ok := s.relations[m.RelationID]
+	if !ok {
+		return nil, fmt.Errorf("unknown relation id %d", m.RelationID)
+	}
+	if rel.RelationName != "outbox" {
+		return nil, nil
+	}
+
+	e := &outbox.Event{Producer: "unknown"}
+	for i, col := range m.Tuple.Columns {
+		if i >= len(rel.Columns) || col.DataType != pglogrepl.TupleDataTypeText {
+			continue
+		}
+		value := string(col.Data)
+		switch rel.Columns[i].Name {
+		case "id":
+			e.ID = value
+		case "event_type":
+			e.EventType = value
+		case "payload":
+			e.Payload = col.Data
+		case "status":
+			e.Status = value
+		case "correlation_id":
+			e.CorrelationID = value
+		case "causation_id":
+			e.CausationID = value
+		case "producer":
+			e.Producer = value
+		case "schema_version":
+			v, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("decode outbox schema_version: %w", err)
+			}
+			e.SchemaVersion = v
+		case "created_at":
+			if err := s.typeMap.Scan(rel.Columns[i].DataType, pgtype.TextFormatCode, col.Data, &e.CreatedAt); err != nil {
+				return nil, fmt.Errorf("decode outbox created_at: %w", err)
+			}
+		}
+	}
+	e.UpdatedAt = e.CreatedAt
+
+	return e, nil
+}
@@ -19,10 +19,12 @@ func NewPaymentRepository(pool *pgxpool.Pool) *PaymentRepository {
 	return &PaymentRepository{pool: pool}
 }
 
-func (r *PaymentRepository) Create(ctx context.Context, p *payment.Payment) error {
+// Create inserts p unless the order already has a payment. It returns true if
+// p was inserted, false if another payment of the order exists.
+func (r *PaymentRepository) Create(ctx context.Context, p *payment.Payment) (bool, error) {
 	const sql = `
-		INSERT INTO payments (id, order_id, status, amount, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO payments (id, order_id, status, amount, failure_reason, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		ON CONFLICT (order_id) DO NOTHING
 	`
 
@@ -34,23 +36,33 @@ func (r *PaymentRepository) Create(ctx context.Context, p *payment.Payment) erro
 		executor = tx
 	}
 
-	_, err := executor.Exec(ctx, sql, p.ID, p.OrderID, p.Status, p.Amount, p.CreatedAt, p.UpdatedAt)
+	tag, err := executor.Exec(ctx, sql, p.ID, p.OrderID, p.Status, p.Amount, nullIfEmpty(p.FailureReason), p.CreatedAt, p.UpdatedAt)
 	if err != nil {
-		return fmt.Errorf("insert payment: %w", err)
+		return false, fmt.Errorf("insert payment: %w", err)
 	}
 
-	return nil
+	return tag.RowsAffected() > 0, nil
 }
 
+// GetByOrderID returns the payment of the order, or nil if there is none. It
+// reads inside the transaction from ctx, if any.
 func (r *PaymentRepository) GetByOrderID(ctx context.Context, orderID string) (*payment.Payment, error) {
 	const sql = `
-		SELECT id, order_id, status, amount, created_at, updated_at
+		SELECT id, order_id, status, amount, COALESCE(failure_reason, ''), created_at, updated_at
 		FROM payments
 		WHERE order_id = $1
 	`
 
+	var querier interface {
+		QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	} = r.pool
+
+	if tx := GetTx(ctx); tx != nil {
+		querier = tx
+	}
+
 	var p payment.Payment
-	err := r.pool.QueryRow(ctx, sql, orderID).Scan(&p.ID, &p.OrderID, &p.Status, &p.Amount, &p.CreatedAt, &p.UpdatedAt)
+	err := querier.QueryRow(ctx, sql, orderID).Scan(&p.ID, &p.OrderID, &p.Status, &p.Amount, &p.FailureReason, &p.CreatedAt, &p.UpdatedAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
@@ -59,3 +71,32 @@ func (r *PaymentRepository) GetByOrderID(ctx context.Context, orderID string) (*
 	}
 	return &p, nil
 }
+
+// Transition moves the order's payment from one status to another and returns it.
+// It returns nil if there is no payment in the from status (already moved or never created).
+func (r *PaymentRepository) Transition(ctx context.Context, orderID string, from string, to string) (*payment.Payment, error) {
+	const sql = `
+		UPDATE payments
+		SET status = $3, updated_at = NOW()
+		WHERE order_id = $1 AND status = $2
+		RETURNING id, order_id, status, amount, COALESCE(failure_reason, ''), created_at, updated_at
+	`
+
+	var querier interface {
+		QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	} = r.pool
+
+	if tx := GetTx(ctx); tx != nil {
+		querier = tx
+	}
+
+	var p payment.Payment
+	err := querier.QueryRow(ctx, sql, orderID, from, to).Scan(&p.ID, &p.OrderID, &p.Status, &p.Amount, &p.FailureReason, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("transition payment %s -> %s: %w", from, to, err)
+	}
+	return &p, nil
+}
@@ -16,11 +16,14 @@ type Config struct {
 	DBName   string
 }
 
-func NewClient(ctx context.Context, cfg Config) (*pgxpool.Pool, error) {
-	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
+// DSN returns the connection string for cfg.
+func (cfg Config) DSN() string {
+	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
 		cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.DBName)
+}
 
-	poolConfig, err := pgxpool.ParseConfig(dsn)
+func NewClient(ctx context.Context, cfg Config) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(cfg.DSN())
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"project/internal/domain/payment"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RefundRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRefundRepository(pool *pgxpool.Pool) *RefundRepository {
+	return &RefundRepository{pool: pool}
+}
+
+func (r *RefundRepository) Create(ctx context.Context, rf *payment.Refund) error {
+	const sql = `
+		INSERT INTO refunds (id, payment_id, order_id, amount, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (order_id) DO NOTHING
+	`
+
+	var executor interface {
+		Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	} = r.pool
+
+	if tx := GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	_, err := executor.Exec(ctx, sql, rf.ID, rf.PaymentID, rf.OrderID, rf.Amount, nullIfEmpty(rf.Reason), rf.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert refund: %w", err)
+	}
+	return nil
+}
+
+func (r *RefundRepository) GetByOrderID(ctx context.Context, orderID string) (*payment.Refund, error) {
+	const sql = `
+		SELECT id, payment_id, order_id, amount, COALESCE(reason, ''), created_at
+		FROM refunds
+		WHERE order_id = $1
+	`
+
+	var rf payment.Refund
+	err := r.pool.QueryRow(ctx, sql, orderID).Scan(&rf.ID, &rf.PaymentID, &rf.OrderID, &rf.Amount, &rf.Reason, &rf.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get refund by order_id: %w", err)
+	}
+	return &rf, nil
+}
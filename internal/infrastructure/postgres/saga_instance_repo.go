@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"project/internal/domain/saga"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const sagaInstanceColumns = `
+	id, flow, order_id, step, state, retries,
+	COALESCE(command, ''), command_payload, deadline_at, created_at, updated_at
+`
+
+type SagaInstanceRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSagaInstanceRepository(pool *pgxpool.Pool) *SagaInstanceRepository {
+	return &SagaInstanceRepository{pool: pool}
+}
+
+// Create stores a new instance. It returns false if the flow already runs for the order.
+func (r *SagaInstanceRepository) Create(ctx context.Context, i *saga.Instance) (bool, error) {
+	const sql = `
+		INSERT INTO saga_instances (
+			id, flow, order_id, step, state, retries,
+			command, command_payload, deadline_at, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)
+		ON CONFLICT (flow, order_id) DO NOTHING
+	`
+
+	var executor interface {
+		Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	} = r.pool
+
+	if tx := GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	tag, err := executor.Exec(ctx, sql,
+		i.ID, i.Flow, i.OrderID, i.Step, i.State, i.Retries,
+		nullIfEmpty(i.Command), i.CommandPayload, i.DeadlineAt, i.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("insert saga instance: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetByOrderID returns the instance of flow for the order, locked until the end of
+// the transaction in ctx. It returns nil if the order is not orchestrated.
+func (r *SagaInstanceRepository) GetByOrderID(ctx context.Context, flow string, orderID string) (*saga.Instance, error) {
+	sql := "SELECT " + sagaInstanceColumns + " FROM saga_instances WHERE flow = $1 AND order_id = $2 FOR UPDATE"
+
+	var querier interface {
+		QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	} = r.pool
+
+	if tx := GetTx(ctx); tx != nil {
+		querier = tx
+	}
+
+	i, err := scanSagaInstance(querier.QueryRow(ctx, sql, flow, orderID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return i, nil
+}
+
+// ListExpired returns active instances whose deadline has passed, oldest first.
+// The rows are locked and skipped by concurrent orchestrators until the
+// transaction in ctx ends.
+func (r *SagaInstanceRepository) ListExpired(ctx context.Context, limit int) ([]*saga.Instance, error) {
+	sql := "SELECT " + sagaInstanceColumns + ` FROM saga_instances
+		WHERE state IN ('RUNNING', 'COMPENSATING') AND deadline_at < NOW()
+		ORDER BY deadline_at ASC
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`
+
+	var querier interface {
+		Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	} = r.pool
+
+	if tx := GetTx(ctx); tx != nil {
+		querier = tx
+	}
+
+	rows, err := querier.Query(ctx, sql, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query expired saga instances: %w", err)
+	}
+	defer rows.Close()
+
+	var instances []*saga.Instance
+	for rows.Next() {
+		i, err := scanSagaInstance(rows)
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, i)
+	}
+
+	return instances, rows.Err()
+}
+
+// Update saves the step, state, retries, command and deadline of the instance.
+func (r *SagaInstanceRepository) Update(ctx context.Context, i *saga.Instance) error {
+	const sql = `
+		UPDATE saga_instances
+		SET step = $2, state = $3, retries = $4, command = $5, command_payload = $6,
+			deadline_at = $7, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	var executor interface {
+		Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	} = r.pool
+
+	if tx := GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	_, err := executor.Exec(ctx, sql,
+		i.ID, i.Step, i.State, i.Retries, nullIfEmpty(i.Command), i.CommandPayload, i.DeadlineAt)
+	if err != nil {
+		return fmt.Errorf("update saga instance: %w", err)
+	}
+	return nil
+}
+
+func scanSagaInstance(row pgx.Row) (*saga.Instance, error) {
+	var i saga.Instance
+	err := row.Scan(
+		&i.ID, &i.Flow, &i.OrderID, &i.Step, &i.State, &i.Retries,
+		&i.Command, &i.CommandPayload, &i.DeadlineAt, &i.CreatedAt, &i.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan saga instance: %w", err)
+	}
+	return &i, nil
+}
@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SagaTimeoutRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSagaTimeoutRepository(pool *pgxpool.Pool) *SagaTimeoutRepository {
+	return &SagaTimeoutRepository{pool: pool}
+}
+
+// Register records that orderID timed out in status. It returns false if the
+// timeout was already registered, so each stuck step is reported once.
+func (r *SagaTimeoutRepository) Register(ctx context.Context, orderID string, status string, eventType string, stuckSince time.Time) (bool, error) {
+	const sql = `
+		INSERT INTO saga_timeouts (order_id, status, event_type, stuck_since, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (order_id, status) DO NOTHING
+	`
+
+	var executor interface {
+		Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	} = r.pool
+
+	if tx := GetTx(ctx); tx != nil {
+		executor = tx
+	}
+
+	tag, err := executor.Exec(ctx, sql, orderID, status, eventType, stuckSince)
+	if err != nil {
+		return false, fmt.Errorf("insert saga timeout: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -19,17 +19,19 @@ func NewTicketRepository(pool *pgxpool.Pool) *TicketRepository {
 	return &TicketRepository{pool: pool}
 }
 
-func (r *TicketRepository) Create(ctx context.Context, t *ticket.Ticket) error {
+// Create inserts t unless the order already has a ticket. It returns true if
+// t was inserted, false if another ticket of the order exists.
+func (r *TicketRepository) Create(ctx context.Context, t *ticket.Ticket) (bool, error) {
 	const sql = `
 		INSERT INTO tickets (
 			id, order_id,
 			from_city, to_city, travel_date, travel_time, airline,
-			status, created_at, updated_at
+			status, failure_reason, created_at, updated_at
 		)
 		VALUES (
 			$1, $2,
 			$3, $4, NULLIF($5, '')::date, $6, $7,
-			$8, $9, $10
+			$8, $9, $10, $11
 		)
 		ON CONFLICT (order_id) DO NOTHING
 	`
@@ -42,20 +44,22 @@ func (r *TicketRepository) Create(ctx context.Context, t *ticket.Ticket) error {
 		executor = tx
 	}
 
-	_, err := executor.Exec(
+	tag, err := executor.Exec(
 		ctx,
 		sql,
 		t.ID, t.OrderID,
 		nullIfEmptyText(t.FromCity), nullIfEmptyText(t.ToCity), t.TravelDate, nullIfEmptyText(t.TravelTime), nullIfEmptyText(t.Airline),
-		t.Status, t.CreatedAt, t.UpdatedAt,
+		t.Status, nullIfEmpty(t.FailureReason), t.CreatedAt, t.UpdatedAt,
 	)
 	if err != nil {
-		return fmt.Errorf("insert ticket: %w", err)
+		return false, fmt.Errorf("insert ticket: %w", err)
 	}
 
-	return nil
+	return tag.RowsAffected() > 0, nil
 }
 
+// GetByOrderID returns the ticket of the order, or nil if there is none. It
+// reads inside the transaction from ctx, if any.
 func (r *TicketRepository) GetByOrderID(ctx context.Context, orderID string) (*ticket.Ticket, error) {
 	const sql = `
 		SELECT
@@ -65,16 +69,24 @@ func (r *TicketRepository) GetByOrderID(ctx context.Context, orderID string) (*t
 			COALESCE(to_char(travel_date, 'YYYY-MM-DD'), ''),
 			COALESCE(travel_time, ''),
 			COALESCE(airline, ''),
-			status, created_at, updated_at
+			status, COALESCE(failure_reason, ''), created_at, updated_at
 		FROM tickets
 		WHERE order_id = $1
 	`
 
+	var querier interface {
+		QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	} = r.pool
+
+	if tx := GetTx(ctx); tx != nil {
+		querier = tx
+	}
+
 	var t ticket.Ticket
-	err := r.pool.QueryRow(ctx, sql, orderID).Scan(
+	err := querier.QueryRow(ctx, sql, orderID).Scan(
 		&t.ID, &t.OrderID,
 		&t.FromCity, &t.ToCity, &t.TravelDate, &t.TravelTime, &t.Airline,
-		&t.Status, &t.CreatedAt, &t.UpdatedAt,
+		&t.Status, &t.FailureReason, &t.CreatedAt, &t.UpdatedAt,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -84,3 +96,63 @@ func (r *TicketRepository) GetByOrderID(ctx context.Context, orderID string) (*t
 	}
 	return &t, nil
 }
+
+// CountIssued returns the number of issued tickets for a flight.
+func (r *TicketRepository) CountIssued(ctx context.Context, f ticket.Flight) (int, error) {
+	const sql = `
+		SELECT COUNT(*)
+		FROM tickets
+		WHERE status = 'ISSUED'
+		  AND from_city IS NOT DISTINCT FROM $1
+		  AND to_city IS NOT DISTINCT FROM $2
+		  AND travel_date IS NOT DISTINCT FROM NULLIF($3, '')::date
+		  AND travel_time IS NOT DISTINCT FROM $4
+		  AND airline IS NOT DISTINCT FROM $5
+	`
+
+	var querier interface {
+		QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	} = r.pool
+
+	if tx := GetTx(ctx); tx != nil {
+		querier = tx
+	}
+
+	var n int
+	err := querier.QueryRow(ctx, sql,
+		nullIfEmptyText(f.FromCity), nullIfEmptyText(f.ToCity), f.TravelDate, nullIfEmptyText(f.TravelTime), nullIfEmptyText(f.Airline),
+	).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("count issued tickets: %w", err)
+	}
+	return n, nil
+}
+
+// Transition moves the order's ticket from one status to another and returns it.
+// It returns nil if there is no ticket in the from status.
+func (r *TicketRepository) Transition(ctx context.Context, orderID string, from string, to string) (*ticket.Ticket, error) {
+	const sql = `
+		UPDATE tickets
+		SET status = $3, updated_at = NOW()
+		WHERE order_id = $1 AND status = $2
+		RETURNING id, order_id, status
+	`
+
+	var querier interface {
+		QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	} = r.pool
+
+	if tx := GetTx(ctx); tx != nil {
+		querier = tx
+	}
+
+	var t ticket.Ticket
+	err := querier.QueryRow(ctx, sql, orderID, from, to).Scan(&t.ID, &t.OrderID, &t.Status)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("transition ticket %s -> %s: %w", from, to, err)
+	}
+	return &t, nil
+}
@@ -40,14 +40,16 @@ func (tm *TxManager) WithinTransaction(ctx context.Context, tFunc func(ctx conte
 		}
 	}()
 
-	// Inject transaction into context
-	// In a real project, use a custom key type to avoid collisions
-	ctxWithTx := context.WithValue(ctx, "tx", tx)
-
-	err = tFunc(ctxWithTx)
+	err = tFunc(WithTx(ctx, tx))
 	return err
 }
 
+// WithTx injects tx into the context so that repositories pick it up via GetTx.
+// In a real project, use a custom key type to avoid collisions
+func WithTx(ctx context.Context, tx pgx.Tx) context.Context {
+	return context.WithValue(ctx, "tx", tx)
+}
+
 // GetTx retrieves the transaction from context, or nil if not present.
 // This allows repositories to support both transactional and non-transactional modes.
 func GetTx(ctx context.Context) pgx.Tx {
@@ -0,0 +1,358 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"project/internal/consumer"
+	domainEvent "project/internal/domain/event"
+	"project/internal/domain/order"
+	"project/internal/domain/outbox"
+	sagaDomain "project/internal/domain/saga"
+	"project/internal/infrastructure/postgres"
+	"project/internal/saga"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Name is the consumer and outbox producer name of the orchestrator.
+const Name = "saga-orchestrator"
+
+var (
+	commandsSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "saga_orchestrator_commands_total",
+		Help: "The total number of commands sent by the orchestrator, including resends",
+	}, []string{"command", "resend"})
+	sagasFinished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "saga_orchestrator_sagas_finished_total",
+		Help: "The total number of orchestrated sagas by final state",
+	}, []string{"state"})
+)
+
+type Config struct {
+	// CommandTimeout is how long to wait for a reply before resending a command.
+	CommandTimeout time.Duration
+	// CommandRetries is how many times a command is resent before the step is given up.
+	CommandRetries int
+	ScanInterval   time.Duration
+	ScanBatchSize  int
+}
+
+type modePayload struct {
+	SagaMode string `json:"saga_mode"`
+}
+
+type failurePayload struct {
+	ReasonCode string `json:"reason_code"`
+}
+
+// emitFunc writes an event to the outbox in the current transaction.
+type emitFunc func(eventType string, payload any) error
+
+// Orchestrator drives orchestrated orders through a saga definition: it sends
+// the command of each step, moves on when the success reply arrives and sends
+// the compensations in reverse order when a step fails. Participants reply with
+// the same events as in choreography; the order service keeps updating the
+// order status from them.
+type Orchestrator struct {
+	def        *saga.Definition
+	txManager  postgres.Transactor
+	sagaRepo   *postgres.SagaInstanceRepository
+	outboxRepo *postgres.OutboxRepository
+	cfg        Config
+	logger     *slog.Logger
+}
+
+// New creates an orchestrator for def, which must be valid and have commands for
+// every step and compensation (see saga.Definition.RequireCommands).
+func New(
+	def *saga.Definition,
+	txManager postgres.Transactor,
+	sagaRepo *postgres.SagaInstanceRepository,
+	outboxRepo *postgres.OutboxRepository,
+	cfg Config,
+	logger *slog.Logger,
+) *Orchestrator {
+	if cfg.CommandTimeout <= 0 {
+		cfg.CommandTimeout = 30 * time.Second
+	}
+	if cfg.CommandRetries < 0 {
+		cfg.CommandRetries = 0
+	}
+	if cfg.ScanInterval <= 0 {
+		cfg.ScanInterval = 15 * time.Second
+	}
+	if cfg.ScanBatchSize <= 0 {
+		cfg.ScanBatchSize = 100
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Orchestrator{
+		def:        def,
+		txManager:  txManager,
+		sagaRepo:   sagaRepo,
+		outboxRepo: outboxRepo,
+		cfg:        cfg,
+		logger:     logger,
+	}
+}
+
+// Register subscribes the orchestrator to the trigger of the first step and to
+// every reply of the flow.
+func (o *Orchestrator) Register(rt *consumer.Runtime) {
+	rt.Handle(o.def.Steps[0].Trigger, o.start)
+
+	for i, s := range o.def.Steps {
+		i := i
+		rt.Handle(s.Success, o.onReply(s.Name, func(ev *consumer.Event, inst *sagaDomain.Instance) error {
+			if i+1 < len(o.def.Steps) {
+				next := o.def.Steps[i+1]
+				return o.send(inst, ev.Emit, next.Name, sagaDomain.StateRunning, next.Command, ev.Payload)
+			}
+			o.finish(inst, sagaDomain.StateCompleted)
+			return nil
+		}))
+
+		for _, f := range s.Failures {
+			rt.Handle(f, o.onReply(s.Name, func(ev *consumer.Event, inst *sagaDomain.Instance) error {
+				var p failurePayload
+				if err := json.Unmarshal(ev.Payload, &p); err != nil {
+					return consumer.Permanent(fmt.Errorf("unmarshal %s payload: %w", ev.Type, err))
+				}
+				return o.compensate(inst, ev.Emit, o.def.CompensationBefore(i), p.ReasonCode)
+			}))
+		}
+
+		if c := s.Compensation; c != nil {
+			rt.Handle(c.Done, o.onReply(c.Name, func(ev *consumer.Event, inst *sagaDomain.Instance) error {
+				var p domainEvent.Compensation
+				if err := json.Unmarshal(inst.CommandPayload, &p); err != nil {
+					return consumer.Permanent(fmt.Errorf("unmarshal command payload of saga %s: %w", inst.ID, err))
+				}
+				return o.compensate(inst, ev.Emit, o.def.CompensationBefore(i), p.Reason)
+			}))
+		}
+	}
+}
+
+func (o *Orchestrator) start(ctx context.Context, ev *consumer.Event) error {
+	var p modePayload
+	if err := json.Unmarshal(ev.Payload, &p); err != nil {
+		return consumer.Permanent(fmt.Errorf("unmarshal %s payload: %w", ev.Type, err))
+	}
+	if p.SagaMode != order.SagaOrchestration {
+		return nil
+	}
+
+	first := o.def.Steps[0]
+	inst := &sagaDomain.Instance{
+		ID:        uuid.New().String(),
+		Flow:      o.def.Name,
+		OrderID:   ev.CorrelationID,
+		CreatedAt: time.Now(),
+	}
+	o.advance(inst, first.Name, sagaDomain.StateRunning, first.Command, ev.Payload)
+
+	created, err := o.sagaRepo.Create(ctx, inst)
+	if err != nil {
+		return err
+	}
+	if !created {
+		o.logger.Info("Saga already started", "flow", inst.Flow, "order_id", inst.OrderID)
+		return nil
+	}
+
+	if err := ev.Emit(inst.Command, inst.CommandPayload); err != nil {
+		return err
+	}
+	commandsSent.WithLabelValues(inst.Command, "false").Inc()
+
+	ev.AfterCommit(func() {
+		o.logger.Info("Saga started", "flow", inst.Flow, "order_id", inst.OrderID, "saga_id", inst.ID)
+	})
+	return nil
+}
+
+// onReply loads the saga of the replying order and runs fn if the saga waits for
+// that reply in step. Replies for choreographed orders or for a step the saga has
+// already left (late replies, duplicates after a resend) are ignored.
+func (o *Orchestrator) onReply(step string, fn func(ev *consumer.Event, inst *sagaDomain.Instance) error) consumer.HandlerFunc {
+	return func(ctx context.Context, ev *consumer.Event) error {
+		inst, err := o.sagaRepo.GetByOrderID(ctx, o.def.Name, ev.CorrelationID)
+		if err != nil {
+			return err
+		}
+		if inst == nil {
+			return nil
+		}
+		if !inst.IsActive() || inst.Step != step {
+			o.logger.Info("Ignoring reply", "type", ev.Type, "order_id", inst.OrderID, "step", inst.Step, "state", inst.State)
+			return nil
+		}
+
+		if err := fn(ev, inst); err != nil {
+			return err
+		}
+		return o.sagaRepo.Update(ctx, inst)
+	}
+}
+
+// advance moves the saga to step, waiting for the reply to command.
+func (o *Orchestrator) advance(inst *sagaDomain.Instance, step, state, command string, payload json.RawMessage) {
+	deadline := time.Now().Add(o.cfg.CommandTimeout)
+
+	inst.Step = step
+	inst.State = state
+	inst.Retries = 0
+	inst.Command = command
+	inst.CommandPayload = payload
+	inst.DeadlineAt = &deadline
+}
+
+// send moves the saga to step and emits command.
+func (o *Orchestrator) send(inst *sagaDomain.Instance, emit emitFunc, step, state, command string, payload json.RawMessage) error {
+	o.advance(inst, step, state, command, payload)
+
+	if err := emit(command, payload); err != nil {
+		return err
+	}
+	commandsSent.WithLabelValues(command, "false").Inc()
+	return nil
+}
+
+// compensate sends the compensation of step i, or finishes the saga if i is -1
+// and nothing is left to compensate.
+func (o *Orchestrator) compensate(inst *sagaDomain.Instance, emit emitFunc, i int, reason string) error {
+	if i < 0 {
+		if inst.State == sagaDomain.StateCompensating {
+			o.finish(inst, sagaDomain.StateCompensated)
+		} else {
+			o.finish(inst, sagaDomain.StateFailed)
+		}
+		return nil
+	}
+
+	c := o.def.Steps[i].Compensation
+	payload, err := json.Marshal(domainEvent.Compensation{OrderID: inst.OrderID, Reason: reason, SagaMode: order.SagaOrchestration})
+	if err != nil {
+		return fmt.Errorf("marshal %s payload: %w", c.Command, err)
+	}
+	return o.send(inst, emit, c.Name, sagaDomain.StateCompensating, c.Command, payload)
+}
+
+func (o *Orchestrator) finish(inst *sagaDomain.Instance, state string) {
+	inst.Step = sagaDomain.StepDone
+	inst.State = state
+	inst.Command = ""
+	inst.CommandPayload = nil
+	inst.DeadlineAt = nil
+	sagasFinished.WithLabelValues(state).Inc()
+}
+
+// RunDeadlines resends commands that got no reply in time and gives a step up
+// after CommandRetries resends, until ctx is cancelled.
+func (o *Orchestrator) RunDeadlines(ctx context.Context) error {
+	ticker := time.NewTicker(o.cfg.ScanInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := o.checkDeadlines(ctx); err != nil {
+				o.logger.Error("failed to check saga deadlines", "error", err)
+			}
+		}
+	}
+}
+
+func (o *Orchestrator) checkDeadlines(ctx context.Context) error {
+	return o.txManager.WithinTransaction(ctx, func(txCtx context.Context) error {
+		instances, err := o.sagaRepo.ListExpired(txCtx, o.cfg.ScanBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, inst := range instances {
+			emit := func(eventType string, payload any) error {
+				return o.emit(txCtx, inst.OrderID, eventType, payload)
+			}
+			if err := o.expire(inst, emit); err != nil {
+				return err
+			}
+			if err := o.sagaRepo.Update(txCtx, inst); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// expire handles a saga whose current command got no reply before the deadline.
+func (o *Orchestrator) expire(inst *sagaDomain.Instance, emit emitFunc) error {
+	if inst.Retries < o.cfg.CommandRetries {
+		deadline := time.Now().Add(o.cfg.CommandTimeout)
+		inst.Retries++
+		inst.DeadlineAt = &deadline
+
+		if err := emit(inst.Command, inst.CommandPayload); err != nil {
+			return err
+		}
+		commandsSent.WithLabelValues(inst.Command, "true").Inc()
+		o.logger.Info("Command resent", "command", inst.Command, "order_id", inst.OrderID, "retry", inst.Retries)
+		return nil
+	}
+
+	o.logger.Warn("Saga step timed out", "step", inst.Step, "order_id", inst.OrderID, "retries", inst.Retries)
+
+	i, isCompensation := o.def.Step(inst.Step)
+	if i < 0 || isCompensation {
+		// Compensation itself got no reply: needs a human.
+		o.finish(inst, sagaDomain.StateFailed)
+		return nil
+	}
+
+	// The timeout event moves the order status like in choreography. The timed
+	// out step is compensated too, since its reply may still arrive.
+	s := o.def.Steps[i]
+	if s.TimeoutEvent != "" {
+		if err := emit(s.TimeoutEvent, domainEvent.SagaTimedOut{
+			OrderID:  inst.OrderID,
+			Status:   o.def.PendingStatus(i),
+			Deadline: o.cfg.CommandTimeout.String(),
+			SagaMode: order.SagaOrchestration,
+		}); err != nil {
+			return err
+		}
+	}
+	return o.compensate(inst, emit, o.def.CompensationBefore(i+1), s.Name+"_TIMEOUT")
+}
+
+// emit writes an event that is not caused by a consumed event (deadline handling).
+func (o *Orchestrator) emit(ctx context.Context, orderID, eventType string, payload any) error {
+	data, ok := payload.(json.RawMessage)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("marshal %s payload: %w", eventType, err)
+		}
+	}
+
+	return o.outboxRepo.Create(ctx, &outbox.Event{
+		ID:            uuid.New().String(),
+		EventType:     eventType,
+		Payload:       data,
+		Status:        "new",
+		CorrelationID: orderID,
+		Producer:      Name,
+		CreatedAt:     time.Now(),
+	})
+}
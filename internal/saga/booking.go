@@ -0,0 +1,56 @@
+package saga
+
+import (
+	"time"
+
+	"project/internal/domain/event"
+	"project/internal/domain/order"
+)
+
+// Step names of the booking flow, also stored in saga_instances.step.
+const (
+	StepAuthorizePayment = "AUTHORIZE_PAYMENT"
+	StepIssueTicket      = "ISSUE_TICKET"
+	StepVoidPayment      = "VOID_PAYMENT"
+)
+
+// Booking is the flight booking flow: authorize the payment, then issue the
+// ticket; a ticket that cannot be issued voids the payment.
+func Booking(paymentTimeout, ticketTimeout time.Duration) *Definition {
+	return &Definition{
+		Name:          "booking",
+		InitialStatus: order.StatusCreated,
+		Steps: []Step{
+			{
+				Name:         StepAuthorizePayment,
+				Participant:  "payment-service",
+				Trigger:      event.TypeOrderCreated,
+				Command:      event.TypeAuthorizePayment,
+				Success:      event.TypePaymentAuthorized,
+				Status:       order.StatusPaymentAuthorized,
+				Failures:     []string{event.TypePaymentFailed},
+				FailedStatus: order.StatusCancelled,
+				Timeout:      paymentTimeout,
+				TimeoutEvent: event.TypePaymentTimedOut,
+				Compensation: &Compensation{
+					Name:    StepVoidPayment,
+					Command: event.TypeVoidPayment,
+					Done:    event.TypePaymentVoided,
+					Status:  order.StatusCancelledCompensated,
+				},
+			},
+			{
+				Name:         StepIssueTicket,
+				Participant:  "ticket-service",
+				Trigger:      event.TypePaymentAuthorized,
+				Command:      event.TypeIssueTicket,
+				Success:      event.TypeTicketIssued,
+				Status:       order.StatusTicketIssued,
+				Failures:     []string{event.TypeTicketFailed},
+				FailedStatus: order.StatusCompensating,
+				Timeout:      ticketTimeout,
+				TimeoutEvent: event.TypeTicketTimedOut,
+			},
+		},
+	}
+}
@@ -0,0 +1,237 @@
+// Package saga declares saga flows: their steps, the events that trigger and
+// complete them, compensations and timeouts. Consumers, the orchestrator, the
+// watchdog and the workflow view all read the flow from a Definition instead of
+// hard-coding event types.
+package saga
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"project/internal/domain/order"
+)
+
+var ErrInvalidDefinition = errors.New("invalid saga definition")
+
+// Definition is a saga flow. Steps run in order; when a step fails, the
+// compensations of the completed steps before it run in reverse order. A step
+// that timed out is compensated as well, because its reply may still arrive.
+type Definition struct {
+	Name string `yaml:"name"`
+	// InitialStatus is the order status before the first step starts.
+	InitialStatus string `yaml:"initial_status"`
+	Steps         []Step `yaml:"steps"`
+}
+
+// Step is one local transaction of a participant.
+type Step struct {
+	Name        string `yaml:"name"`
+	Participant string `yaml:"participant"`
+	// Trigger starts the step in choreography.
+	Trigger string `yaml:"trigger"`
+	// Command starts the step in orchestration; its payload is the payload of Trigger.
+	Command string `yaml:"command"`
+	// Success is the reply that completes the step and moves the order to Status.
+	Success string `yaml:"success"`
+	Status  string `yaml:"status"`
+	// Failures are replies that fail the step and move the order to FailedStatus.
+	Failures     []string `yaml:"failures"`
+	FailedStatus string   `yaml:"failed_status"`
+	// Timeout is how long the step may wait for a reply before TimeoutEvent
+	// is emitted, which fails the step like a failure reply. Zero disables the
+	// watchdog for the step; the orchestrator may still emit TimeoutEvent.
+	Timeout      time.Duration `yaml:"timeout"`
+	TimeoutEvent string        `yaml:"timeout_event"`
+	// Compensation undoes the completed step when a later step fails.
+	Compensation *Compensation `yaml:"compensation"`
+}
+
+// Compensation is the action that semantically undoes a step.
+type Compensation struct {
+	Name    string `yaml:"name"`
+	Command string `yaml:"command"`
+	// Done is the reply confirming the compensation; it moves the order to Status.
+	Done   string `yaml:"done"`
+	Status string `yaml:"status"`
+}
+
+// Validate checks that the definition is complete, that every event has a single
+// meaning and that the statuses it sets are allowed by the order state machine.
+func (d *Definition) Validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidDefinition)
+	}
+	if len(d.Steps) == 0 {
+		return fmt.Errorf("%w: %s has no steps", ErrInvalidDefinition, d.Name)
+	}
+
+	names := make(map[string]bool)
+	successes := make(map[string]bool)
+	events := make(map[string]string)
+	claim := func(event, role string) error {
+		if event == "" {
+			return nil
+		}
+		if prev, ok := events[event]; ok {
+			return fmt.Errorf("%w: %s: event %s is both %s and %s", ErrInvalidDefinition, d.Name, event, prev, role)
+		}
+		events[event] = role
+		return nil
+	}
+	transition := func(from, to, what string) error {
+		if from == "" || to == "" || order.CanTransition(from, to) {
+			return nil
+		}
+		return fmt.Errorf("%w: %s: %s moves the order from %s to %s, which is not allowed", ErrInvalidDefinition, d.Name, what, from, to)
+	}
+
+	for i, s := range d.Steps {
+		if s.Name == "" {
+			return fmt.Errorf("%w: %s: step %d has no name", ErrInvalidDefinition, d.Name, i)
+		}
+		if names[s.Name] {
+			return fmt.Errorf("%w: %s: duplicate step %s", ErrInvalidDefinition, d.Name, s.Name)
+		}
+		names[s.Name] = true
+
+		if s.Trigger == "" && s.Command == "" {
+			return fmt.Errorf("%w: %s: step %s needs a trigger or a command", ErrInvalidDefinition, d.Name, s.Name)
+		}
+		if s.Success == "" {
+			return fmt.Errorf("%w: %s: step %s has no success event", ErrInvalidDefinition, d.Name, s.Name)
+		}
+		if s.Timeout > 0 && s.TimeoutEvent == "" {
+			return fmt.Errorf("%w: %s: step %s has a timeout but no timeout_event", ErrInvalidDefinition, d.Name, s.Name)
+		}
+
+		if err := claim(s.Command, "command of "+s.Name); err != nil {
+			return err
+		}
+		if err := claim(s.Success, "success of "+s.Name); err != nil {
+			return err
+		}
+		successes[s.Success] = true
+		for _, f := range s.Failures {
+			if err := claim(f, "failure of "+s.Name); err != nil {
+				return err
+			}
+		}
+		if err := claim(s.TimeoutEvent, "timeout of "+s.Name); err != nil {
+			return err
+		}
+
+		pending := d.PendingStatus(i)
+		if err := transition(pending, s.Status, "success of "+s.Name); err != nil {
+			return err
+		}
+		if err := transition(pending, s.FailedStatus, "failure of "+s.Name); err != nil {
+			return err
+		}
+
+		if c := s.Compensation; c != nil {
+			if c.Name == "" || c.Done == "" {
+				return fmt.Errorf("%w: %s: compensation of %s needs a name and a done event", ErrInvalidDefinition, d.Name, s.Name)
+			}
+			if names[c.Name] {
+				return fmt.Errorf("%w: %s: duplicate step %s", ErrInvalidDefinition, d.Name, c.Name)
+			}
+			names[c.Name] = true
+
+			if err := claim(c.Command, "command of "+c.Name); err != nil {
+				return err
+			}
+			if err := claim(c.Done, "done of "+c.Name); err != nil {
+				return err
+			}
+			// Compensation runs after a later step failed.
+			for _, later := range d.Steps[i+1:] {
+				if err := transition(later.FailedStatus, c.Status, c.Name+" after "+later.Name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// A trigger may be the success of the previous step, but must not be a
+	// failure, timeout or compensation event.
+	for _, s := range d.Steps {
+		if role, ok := events[s.Trigger]; ok && !successes[s.Trigger] {
+			return fmt.Errorf("%w: %s: step %s is triggered by %s, which is the %s", ErrInvalidDefinition, d.Name, s.Name, s.Trigger, role)
+		}
+	}
+
+	return nil
+}
+
+// Step returns the index of the step or compensation with the given name, and
+// whether the name is a compensation. The index is -1 if there is no such step.
+func (d *Definition) Step(name string) (int, bool) {
+	for i, s := range d.Steps {
+		if s.Name == name {
+			return i, false
+		}
+		if s.Compensation != nil && s.Compensation.Name == name {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// PendingStatus returns the order status while step i waits for its reply.
+func (d *Definition) PendingStatus(i int) string {
+	for j := i - 1; j >= 0; j-- {
+		if d.Steps[j].Status != "" {
+			return d.Steps[j].Status
+		}
+	}
+	return d.InitialStatus
+}
+
+// CompensationBefore returns the index of the closest step before i that has a
+// compensation, or -1 if nothing is left to compensate.
+func (d *Definition) CompensationBefore(i int) int {
+	for j := i - 1; j >= 0; j-- {
+		if d.Steps[j].Compensation != nil {
+			return j
+		}
+	}
+	return -1
+}
+
+// StatusTransitions maps each event of the flow that changes the order status
+// to the status it sets.
+func (d *Definition) StatusTransitions() map[string]string {
+	transitions := make(map[string]string)
+	for _, s := range d.Steps {
+		if s.Status != "" {
+			transitions[s.Success] = s.Status
+		}
+		if s.FailedStatus != "" {
+			for _, f := range s.Failures {
+				transitions[f] = s.FailedStatus
+			}
+			if s.TimeoutEvent != "" {
+				transitions[s.TimeoutEvent] = s.FailedStatus
+			}
+		}
+		if c := s.Compensation; c != nil && c.Status != "" {
+			transitions[c.Done] = c.Status
+		}
+	}
+	return transitions
+}
+
+// RequireCommands checks that every step and compensation can be started by a
+// command, which orchestration needs.
+func (d *Definition) RequireCommands() error {
+	for _, s := range d.Steps {
+		if s.Command == "" {
+			return fmt.Errorf("%w: %s: step %s has no command", ErrInvalidDefinition, d.Name, s.Name)
+		}
+		if c := s.Compensation; c != nil && c.Command == "" {
+			return fmt.Errorf("%w: %s: compensation %s has no command", ErrInvalidDefinition, d.Name, c.Name)
+		}
+	}
+	return nil
+}
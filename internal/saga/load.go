@@ -0,0 +1,41 @@
+package saga
+
+import (
+	"fmt"
+	"os"
+
+	"project/internal/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Load reads a definition from a YAML file and validates it.
+func Load(path string) (*Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read saga definition: %w", err)
+	}
+
+	var d Definition
+	if err := yaml.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("parse saga definition %s: %w", path, err)
+	}
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// LoadBooking returns the booking flow from cfg.DefinitionFile if set, or the
+// built-in one with the configured deadlines, validated.
+func LoadBooking(cfg config.Saga) (*Definition, error) {
+	if cfg.DefinitionFile != "" {
+		return Load(cfg.DefinitionFile)
+	}
+
+	d := Booking(cfg.PaymentDeadline, cfg.TicketDeadline)
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
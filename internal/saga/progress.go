@@ -0,0 +1,93 @@
+package saga
+
+import "time"
+
+// Step progress states.
+const (
+	ProgressPending      = "pending"
+	ProgressRunning      = "running"
+	ProgressCompleted    = "completed"
+	ProgressFailed       = "failed"
+	ProgressTimedOut     = "timed_out"
+	ProgressCompensating = "compensating"
+	ProgressCompensated  = "compensated"
+)
+
+// Occurrence is an event that happened in a saga.
+type Occurrence struct {
+	Type string
+	At   time.Time
+}
+
+// StepProgress compares a step with what actually happened: Expected is the
+// event the step waits for, Event and At the event that decided its state.
+type StepProgress struct {
+	Step        string     `json:"step"`
+	Participant string     `json:"participant"`
+	Expected    string     `json:"expected"`
+	State       string     `json:"state"`
+	Event       string     `json:"event,omitempty"`
+	At          *time.Time `json:"at,omitempty"`
+}
+
+// Progress returns the state of every step given the events of one saga.
+func (d *Definition) Progress(occurred []Occurrence) []StepProgress {
+	first := make(map[string]time.Time)
+	for _, o := range occurred {
+		if at, ok := first[o.Type]; !ok || o.At.Before(at) {
+			first[o.Type] = o.At
+		}
+	}
+	seen := func(events ...string) (string, *time.Time) {
+		for _, e := range events {
+			if at, ok := first[e]; ok {
+				return e, &at
+			}
+		}
+		return "", nil
+	}
+
+	// failedAfter reports whether a step after i failed or timed out, which
+	// makes the compensation of step i due.
+	failedAfter := func(i int) bool {
+		for _, s := range d.Steps[i+1:] {
+			if e, _ := seen(append([]string{s.TimeoutEvent}, s.Failures...)...); e != "" {
+				return true
+			}
+		}
+		return false
+	}
+
+	progress := make([]StepProgress, 0, len(d.Steps))
+	for i, s := range d.Steps {
+		p := StepProgress{
+			Step:        s.Name,
+			Participant: s.Participant,
+			Expected:    s.Success,
+			State:       ProgressPending,
+		}
+
+		if e, at := seen(s.Success); e != "" {
+			p.State, p.Event, p.At = ProgressCompleted, e, at
+		} else if e, at := seen(s.Failures...); e != "" {
+			p.State, p.Event, p.At = ProgressFailed, e, at
+		} else if e, at := seen(s.TimeoutEvent); e != "" {
+			p.State, p.Event, p.At = ProgressTimedOut, e, at
+		} else if e, at := seen(s.Trigger, s.Command); e != "" {
+			p.State, p.Event, p.At = ProgressRunning, e, at
+		}
+
+		if c := s.Compensation; c != nil && p.State == ProgressCompleted {
+			if e, at := seen(c.Done); e != "" {
+				p.State, p.Event, p.At = ProgressCompensated, e, at
+			} else if e, at := seen(c.Command); e != "" {
+				p.State, p.Event, p.At = ProgressCompensating, e, at
+			} else if failedAfter(i) {
+				p.State = ProgressCompensating
+			}
+		}
+
+		progress = append(progress, p)
+	}
+	return progress
+}
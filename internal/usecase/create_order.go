@@ -3,9 +3,11 @@ package usecase
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
+	domainEvent "project/internal/domain/event"
 	"project/internal/domain/order"
 	"project/internal/domain/outbox"
 	"project/internal/infrastructure/postgres"
@@ -14,20 +16,29 @@ import (
 )
 
 type CreateOrder struct {
-	txManager  postgres.Transactor
-	orderRepo  *postgres.OrderRepository
-	outboxRepo *postgres.OutboxRepository
+	txManager       postgres.Transactor
+	orderRepo       *postgres.OrderRepository
+	outboxRepo      *postgres.OutboxRepository
+	defaultSagaMode string
 }
 
+// NewCreateOrder creates the use case. defaultSagaMode is used for orders that
+// do not ask for a saga mode themselves.
 func NewCreateOrder(
 	txManager postgres.Transactor,
 	orderRepo *postgres.OrderRepository,
 	outboxRepo *postgres.OutboxRepository,
+	defaultSagaMode string,
 ) *CreateOrder {
+	if defaultSagaMode == "" {
+		defaultSagaMode = order.SagaChoreography
+	}
+
 	return &CreateOrder{
-		txManager:  txManager,
-		orderRepo:  orderRepo,
-		outboxRepo: outboxRepo,
+		txManager:       txManager,
+		orderRepo:       orderRepo,
+		outboxRepo:      outboxRepo,
+		defaultSagaMode: defaultSagaMode,
 	}
 }
 
@@ -39,32 +50,58 @@ type CreateOrderParams struct {
 	Date    string  `json:"date"`
 	Time    string  `json:"time"`
 	Airline string  `json:"airline"`
+	// SagaMode picks choreography or orchestration for this order; empty means the default.
+	SagaMode string `json:"saga_mode"`
 }
 
+var ErrInvalidSagaMode = errors.New("invalid saga mode")
+
 func (uc *CreateOrder) Execute(ctx context.Context, params CreateOrderParams) (string, error) {
+	sagaMode := params.SagaMode
+	if sagaMode == "" {
+		sagaMode = uc.defaultSagaMode
+	}
+	if sagaMode != order.SagaChoreography && sagaMode != order.SagaOrchestration {
+		return "", fmt.Errorf("%w: %q", ErrInvalidSagaMode, sagaMode)
+	}
+
 	newOrder := &order.Order{
 		ID:          uuid.New().String(),
 		UserID:      params.UserID,
-		Status:      "CREATED",
+		Status:      order.StatusCreated,
 		TotalAmount: params.Amount,
 		FromCity:    params.From,
 		ToCity:      params.To,
 		TravelDate:  params.Date,
 		TravelTime:  params.Time,
 		Airline:     params.Airline,
+		SagaMode:    sagaMode,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
 
 	// Prepare outbox event
-	payload, err := json.Marshal(newOrder)
+	payload, err := json.Marshal(domainEvent.OrderCreated{
+		OrderID:     newOrder.ID,
+		UserID:      newOrder.UserID,
+		Status:      newOrder.Status,
+		TotalAmount: newOrder.TotalAmount,
+		FromCity:    newOrder.FromCity,
+		ToCity:      newOrder.ToCity,
+		TravelDate:  newOrder.TravelDate,
+		TravelTime:  newOrder.TravelTime,
+		Airline:     newOrder.Airline,
+		SagaMode:    newOrder.SagaMode,
+		CreatedAt:   newOrder.CreatedAt,
+		UpdatedAt:   newOrder.UpdatedAt,
+	})
 	if err != nil {
 		return "", fmt.Errorf("marshal order: %w", err)
 	}
 
 	outboxEvent := &outbox.Event{
 		ID:            uuid.New().String(),
-		EventType:     "OrderCreated",
+		EventType:     domainEvent.TypeOrderCreated,
 		Payload:       payload,
 		Status:        "new",
 		CorrelationID: newOrder.ID,
@@ -79,6 +116,10 @@ func (uc *CreateOrder) Execute(ctx context.Context, params CreateOrderParams) (s
 			return err
 		}
 
+		if err := uc.orderRepo.AddInitialHistory(txCtx, newOrder, "order-service"); err != nil {
+			return err
+		}
+
 		if err := uc.outboxRepo.Create(txCtx, outboxEvent); err != nil {
 			return err
 		}
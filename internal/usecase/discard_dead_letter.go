@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"project/internal/domain/deadletter"
+	"project/internal/infrastructure/postgres"
+)
+
+type DiscardDeadLetter struct {
+	deadLetterRepo *postgres.DeadLetterRepository
+}
+
+func NewDiscardDeadLetter(deadLetterRepo *postgres.DeadLetterRepository) *DiscardDeadLetter {
+	return &DiscardDeadLetter{deadLetterRepo: deadLetterRepo}
+}
+
+func (uc *DiscardDeadLetter) Execute(ctx context.Context, id string) error {
+	if err := uc.deadLetterRepo.UpdateStatus(ctx, id, deadletter.StatusDiscarded); err != nil {
+		return fmt.Errorf("discard dead letter: %w", err)
+	}
+	return nil
+}
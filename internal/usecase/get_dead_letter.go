@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"project/internal/infrastructure/postgres"
+)
+
+type GetDeadLetter struct {
+	deadLetterRepo *postgres.DeadLetterRepository
+}
+
+func NewGetDeadLetter(deadLetterRepo *postgres.DeadLetterRepository) *GetDeadLetter {
+	return &GetDeadLetter{deadLetterRepo: deadLetterRepo}
+}
+
+func (uc *GetDeadLetter) Execute(ctx context.Context, id string) (*DeadLetterDTO, error) {
+	m, err := uc.deadLetterRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get dead letter: %w", err)
+	}
+	return newDeadLetterDTO(m, true), nil
+}
@@ -21,6 +21,7 @@ type OrderDTO struct {
 	TravelDate  string    `json:"travel_date"`
 	TravelTime  string    `json:"travel_time"`
 	Airline     string    `json:"airline"`
+	SagaMode    string    `json:"saga_mode"`
 	CreatedAt   time.Time `json:"created_at"`
 }
 
@@ -64,6 +65,7 @@ func (uc *GetOrder) Execute(ctx context.Context, orderID string) (*OrderDTO, err
 		TravelDate:  dbOrder.TravelDate,
 		TravelTime:  dbOrder.TravelTime,
 		Airline:     dbOrder.Airline,
+		SagaMode:    dbOrder.SagaMode,
 		CreatedAt:   dbOrder.CreatedAt,
 	}
 
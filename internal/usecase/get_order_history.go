@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"project/internal/domain/order"
+	"project/internal/infrastructure/postgres"
+)
+
+// HistoryEntryDTO is a status change with the time the order spent in the previous status.
+type HistoryEntryDTO struct {
+	FromStatus  string    `json:"from_status,omitempty"`
+	ToStatus    string    `json:"to_status"`
+	ChangedBy   string    `json:"changed_by"`
+	EventType   string    `json:"event_type,omitempty"`
+	CausationID string    `json:"causation_id,omitempty"`
+	ChangedAt   time.Time `json:"changed_at"`
+	// SincePreviousMs is the time between the previous change and this one.
+	SincePreviousMs int64 `json:"since_previous_ms"`
+}
+
+type GetOrderHistory struct {
+	orderRepo *postgres.OrderRepository
+}
+
+func NewGetOrderHistory(orderRepo *postgres.OrderRepository) *GetOrderHistory {
+	return &GetOrderHistory{orderRepo: orderRepo}
+}
+
+func (uc *GetOrderHistory) Execute(ctx context.Context, orderID string) ([]*HistoryEntryDTO, error) {
+	if _, err := uc.orderRepo.GetByID(ctx, orderID); err != nil {
+		return nil, fmt.Errorf("get order: %w", err)
+	}
+
+	return loadHistory(ctx, uc.orderRepo, orderID)
+}
+
+type historyLister interface {
+	ListHistory(ctx context.Context, id string) ([]*order.HistoryEntry, error)
+}
+
+func loadHistory(ctx context.Context, orderRepo historyLister, orderID string) ([]*HistoryEntryDTO, error) {
+	entries, err := orderRepo.ListHistory(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("get order history: %w", err)
+	}
+
+	history := make([]*HistoryEntryDTO, 0, len(entries))
+	for i, e := range entries {
+		dto := &HistoryEntryDTO{
+			FromStatus:  e.FromStatus,
+			ToStatus:    e.ToStatus,
+			ChangedBy:   e.ChangedBy,
+			EventType:   e.EventType,
+			CausationID: e.CausationID,
+			ChangedAt:   e.ChangedAt,
+		}
+		if i > 0 {
+			dto.SincePreviousMs = e.ChangedAt.Sub(entries[i-1].ChangedAt).Milliseconds()
+		}
+		history = append(history, dto)
+	}
+	return history, nil
+}
@@ -9,14 +9,19 @@ import (
 	"project/internal/domain/payment"
 	"project/internal/domain/ticket"
 	"project/internal/infrastructure/postgres"
+	"project/internal/saga"
 )
 
 type WorkflowDTO struct {
-	Order   *OrderDTO        `json:"order"`
-	Outbox  []*outbox.Event  `json:"outbox"`
-	Inbox   []*inbox.Event   `json:"inbox"`
-	Payment *payment.Payment `json:"payment,omitempty"`
-	Ticket  *ticket.Ticket   `json:"ticket,omitempty"`
+	Order   *OrderDTO          `json:"order"`
+	Outbox  []*outbox.Event    `json:"outbox"`
+	Inbox   []*inbox.Event     `json:"inbox"`
+	Payment *payment.Payment   `json:"payment,omitempty"`
+	Ticket  *ticket.Ticket     `json:"ticket,omitempty"`
+	Refund  *payment.Refund    `json:"refund,omitempty"`
+	History []*HistoryEntryDTO `json:"history"`
+	// Progress is the expected flow step by step, with what actually happened.
+	Progress []saga.StepProgress `json:"progress"`
 }
 
 type GetWorkflow struct {
@@ -25,6 +30,8 @@ type GetWorkflow struct {
 	inboxRepo   *postgres.InboxRepository
 	paymentRepo *postgres.PaymentRepository
 	ticketRepo  *postgres.TicketRepository
+	refundRepo  *postgres.RefundRepository
+	flow        *saga.Definition
 }
 
 func NewGetWorkflow(
@@ -33,6 +40,8 @@ func NewGetWorkflow(
 	inboxRepo *postgres.InboxRepository,
 	paymentRepo *postgres.PaymentRepository,
 	ticketRepo *postgres.TicketRepository,
+	refundRepo *postgres.RefundRepository,
+	flow *saga.Definition,
 ) *GetWorkflow {
 	return &GetWorkflow{
 		orderRepo:   orderRepo,
@@ -40,6 +49,8 @@ func NewGetWorkflow(
 		inboxRepo:   inboxRepo,
 		paymentRepo: paymentRepo,
 		ticketRepo:  ticketRepo,
+		refundRepo:  refundRepo,
+		flow:        flow,
 	}
 }
 
@@ -59,6 +70,7 @@ func (uc *GetWorkflow) Execute(ctx context.Context, orderID string) (*WorkflowDT
 		TravelDate:  dbOrder.TravelDate,
 		TravelTime:  dbOrder.TravelTime,
 		Airline:     dbOrder.Airline,
+		SagaMode:    dbOrder.SagaMode,
 		CreatedAt:   dbOrder.CreatedAt,
 	}
 
@@ -82,11 +94,29 @@ func (uc *GetWorkflow) Execute(ctx context.Context, orderID string) (*WorkflowDT
 		return nil, fmt.Errorf("get ticket: %w", err)
 	}
 
+	rf, err := uc.refundRepo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("get refund: %w", err)
+	}
+
+	history, err := loadHistory(ctx, uc.orderRepo, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	occurred := make([]saga.Occurrence, 0, len(outboxEvents))
+	for _, e := range outboxEvents {
+		occurred = append(occurred, saga.Occurrence{Type: e.EventType, At: e.CreatedAt})
+	}
+
 	return &WorkflowDTO{
-		Order:   order,
-		Outbox:  outboxEvents,
-		Inbox:   inboxEvents,
-		Payment: p,
-		Ticket:  t,
+		Order:    order,
+		Outbox:   outboxEvents,
+		Inbox:    inboxEvents,
+		Payment:  p,
+		Ticket:   t,
+		Refund:   rf,
+		History:  history,
+		Progress: uc.flow.Progress(occurred),
 	}, nil
 }
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"time"
 
+	domainEvent "project/internal/domain/event"
+	"project/internal/domain/order"
 	"project/internal/domain/outbox"
 	"project/internal/infrastructure/postgres"
 
@@ -35,15 +37,9 @@ type RefundOrderParams struct {
 	Reason  string `json:"reason"`
 }
 
-type RefundEvent struct {
-	OrderID   string    `json:"order_id"`
-	Reason    string    `json:"reason"`
-	Timestamp time.Time `json:"timestamp"`
-}
-
 func (uc *RefundOrder) Execute(ctx context.Context, params RefundOrderParams) error {
 	// Prepare outbox event
-	eventPayload := RefundEvent{
+	eventPayload := domainEvent.RefundInitiated{
 		OrderID:   params.OrderID,
 		Reason:    params.Reason,
 		Timestamp: time.Now(),
@@ -56,7 +52,7 @@ func (uc *RefundOrder) Execute(ctx context.Context, params RefundOrderParams) er
 
 	outboxEvent := &outbox.Event{
 		ID:            uuid.New().String(),
-		EventType:     "RefundInitiated",
+		EventType:     domainEvent.TypeRefundInitiated,
 		Payload:       payload,
 		Status:        "new",
 		CorrelationID: params.OrderID,
@@ -68,7 +64,11 @@ func (uc *RefundOrder) Execute(ctx context.Context, params RefundOrderParams) er
 	// Execute in transaction
 	err = uc.txManager.WithinTransaction(ctx, func(txCtx context.Context) error {
 		// 1. Update Order Status
-		if err := uc.orderRepo.UpdateStatus(txCtx, params.OrderID, "REFUND_PENDING"); err != nil {
+		// Only a TICKET_ISSUED order can be refunded; the repository enforces it.
+		if err := uc.orderRepo.UpdateStatus(txCtx, params.OrderID, order.StatusChange{
+			To:        order.StatusRefundPending,
+			ChangedBy: "order-service",
+		}); err != nil {
 			return err
 		}
 
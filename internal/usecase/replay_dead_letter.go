@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"project/internal/domain/deadletter"
+	domainEvent "project/internal/domain/event"
+	"project/internal/infrastructure/eventcodec"
+	"project/internal/infrastructure/kafka"
+	"project/internal/infrastructure/postgres"
+)
+
+// ReplayDeadLetter re-injects a dead letter into the topic it was read from.
+// The original message bytes are sent unchanged, so the envelope keeps its
+// event ID and consumers that already processed it skip it via the inbox.
+// Metadata headers are rebuilt from the envelope, decoded with the codec the
+// message was written with.
+type ReplayDeadLetter struct {
+	deadLetterRepo *postgres.DeadLetterRepository
+	kafkaProd      *kafka.Producer
+}
+
+func NewReplayDeadLetter(deadLetterRepo *postgres.DeadLetterRepository, kafkaProd *kafka.Producer) *ReplayDeadLetter {
+	return &ReplayDeadLetter{
+		deadLetterRepo: deadLetterRepo,
+		kafkaProd:      kafkaProd,
+	}
+}
+
+func (uc *ReplayDeadLetter) Execute(ctx context.Context, id string) error {
+	m, err := uc.deadLetterRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get dead letter: %w", err)
+	}
+
+	if m.Status == deadletter.StatusDiscarded {
+		return fmt.Errorf("replay dead letter %s: %w", id, deadletter.ErrDiscarded)
+	}
+
+	msg := kafka.Message{Topic: m.Topic, Key: m.Key, Value: m.Payload}
+	if ev, err := eventcodec.Decode(m.ContentType, m.Payload); err == nil {
+		msg.Headers = ev.Headers()
+		msg.Headers[domainEvent.HeaderContentType] = m.ContentType
+	}
+
+	if err := uc.kafkaProd.SendBatch(ctx, []kafka.Message{msg})[0]; err != nil {
+		return fmt.Errorf("replay dead letter %s: %w", id, err)
+	}
+
+	if err := uc.deadLetterRepo.UpdateStatus(ctx, id, deadletter.StatusReplayed); err != nil {
+		return fmt.Errorf("mark dead letter replayed: %w", err)
+	}
+
+	return nil
+}
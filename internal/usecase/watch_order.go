@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"project/internal/domain/inbox"
+	"project/internal/domain/order"
+	"project/internal/domain/outbox"
+)
+
+// OrderUpdateDTO is one change of a watched order; exactly one field is set.
+type OrderUpdateDTO struct {
+	// Order is the snapshot sent when the watch starts.
+	Order        *OrderDTO        `json:"order,omitempty"`
+	StatusChange *HistoryEntryDTO `json:"status_change,omitempty"`
+	// Outbox is sent when the event is written and on every status change.
+	Outbox *outbox.Event `json:"outbox,omitempty"`
+	Inbox  *inbox.Event  `json:"inbox,omitempty"`
+}
+
+// The reads and notifications WatchOrder depends on; implemented by
+// postgres.OrderRepository, OutboxRepository, InboxRepository and OrderListener.
+type (
+	WatchedOrders interface {
+		GetByID(ctx context.Context, id string) (*order.Order, error)
+		ListHistory(ctx context.Context, id string) ([]*order.HistoryEntry, error)
+	}
+	WatchedOutbox interface {
+		ListByCorrelationID(ctx context.Context, correlationID string) ([]*outbox.Event, error)
+	}
+	WatchedInbox interface {
+		ListByCorrelationID(ctx context.Context, correlationID string) ([]*inbox.Event, error)
+	}
+	OrderChanges interface {
+		// Subscribe returns a channel woken up after changes of orderID and a
+		// func that releases it.
+		Subscribe(orderID string) (<-chan struct{}, func())
+	}
+)
+
+// WatchOrder streams the changes of an order as they are committed: status
+// changes and the inbox/outbox transitions of its saga. It is woken up by
+// Postgres notifications (OrderListener) and reads what changed from the same
+// tables as GetWorkflow, so a missed or coalesced notification only delays an
+// update.
+type WatchOrder struct {
+	orderRepo  WatchedOrders
+	outboxRepo WatchedOutbox
+	inboxRepo  WatchedInbox
+	listener   OrderChanges
+}
+
+func NewWatchOrder(
+	orderRepo WatchedOrders,
+	outboxRepo WatchedOutbox,
+	inboxRepo WatchedInbox,
+	listener OrderChanges,
+) *WatchOrder {
+	return &WatchOrder{
+		orderRepo:  orderRepo,
+		outboxRepo: outboxRepo,
+		inboxRepo:  inboxRepo,
+		listener:   listener,
+	}
+}
+
+// watchState is what a watch has already sent.
+type watchState struct {
+	history int
+	inbox   map[string]bool
+	outbox  map[string]string // event id -> status
+}
+
+// Execute sends the order, then everything already recorded for it, then each
+// change until ctx is done. It returns order.ErrNotFound (wrapped) for unknown
+// orders and the first error of send.
+func (uc *WatchOrder) Execute(ctx context.Context, orderID string, send func(*OrderUpdateDTO) error) error {
+	// Subscribe before the first read so no change falls in between.
+	changes, unsubscribe := uc.listener.Subscribe(orderID)
+	defer unsubscribe()
+
+	dbOrder, err := uc.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("get order: %w", err)
+	}
+
+	err = send(&OrderUpdateDTO{Order: &OrderDTO{
+		ID:          dbOrder.ID,
+		UserID:      dbOrder.UserID,
+		TotalAmount: dbOrder.TotalAmount,
+		Status:      dbOrder.Status,
+		FromCity:    dbOrder.FromCity,
+		ToCity:      dbOrder.ToCity,
+		TravelDate:  dbOrder.TravelDate,
+		TravelTime:  dbOrder.TravelTime,
+		Airline:     dbOrder.Airline,
+		SagaMode:    dbOrder.SagaMode,
+		CreatedAt:   dbOrder.CreatedAt,
+	}})
+	if err != nil {
+		return err
+	}
+
+	state := &watchState{
+		inbox:  make(map[string]bool),
+		outbox: make(map[string]string),
+	}
+	for {
+		if err := uc.sendChanges(ctx, orderID, state, send); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changes:
+		}
+	}
+}
+
+// sendChanges sends what was recorded for the order since the last call.
+func (uc *WatchOrder) sendChanges(ctx context.Context, orderID string, state *watchState, send func(*OrderUpdateDTO) error) error {
+	history, err := loadHistory(ctx, uc.orderRepo, orderID)
+	if err != nil {
+		return err
+	}
+	for _, h := range history[min(state.history, len(history)):] {
+		if err := send(&OrderUpdateDTO{StatusChange: h}); err != nil {
+			return err
+		}
+	}
+	state.history = max(state.history, len(history))
+
+	outboxEvents, err := uc.outboxRepo.ListByCorrelationID(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("get outbox events: %w", err)
+	}
+	for _, e := range outboxEvents {
+		if status, ok := state.outbox[e.ID]; ok && status == e.Status {
+			continue
+		}
+		if err := send(&OrderUpdateDTO{Outbox: e}); err != nil {
+			return err
+		}
+		state.outbox[e.ID] = e.Status
+	}
+
+	inboxEvents, err := uc.inboxRepo.ListByCorrelationID(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("get inbox events: %w", err)
+	}
+	for _, e := range inboxEvents {
+		key := e.Consumer + "/" + e.EventID
+		if state.inbox[key] {
+			continue
+		}
+		if err := send(&OrderUpdateDTO{Inbox: e}); err != nil {
+			return err
+		}
+		state.inbox[key] = true
+	}
+
+	return nil
+}
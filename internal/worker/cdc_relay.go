@@ -0,0 +1,209 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"project/internal/domain/outbox"
+	"project/internal/infrastructure/eventcodec"
+	"project/internal/infrastructure/kafka"
+	"project/internal/infrastructure/postgres"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	cdcAckedLSN = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "worker_outbox_cdc_acked_lsn",
+		Help: "The last WAL position acknowledged to the outbox replication slot",
+	})
+	cdcDuplicatesSkipped = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "worker_outbox_cdc_duplicates_skipped_total",
+		Help: "The total number of streamed outbox events skipped because they were already processed",
+	})
+	cdcReconnects = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "worker_outbox_cdc_reconnects_total",
+		Help: "The total number of times the replication stream was restarted after an error",
+	})
+)
+
+// CDCConfig controls the replication relay. WorkerID and LeaseDuration are
+// used when claiming rows that were inserted before the slot existed.
+type CDCConfig struct {
+	WorkerID      string
+	LeaseDuration time.Duration
+	RetryDelay    time.Duration
+	// Codecs picks the envelope encoding of each topic; nil means JSON.
+	Codecs *eventcodec.Selector
+}
+
+// OutboxCDCRelay publishes outbox inserts streamed from a logical replication
+// slot instead of polling the table. A transaction's LSN is acknowledged only
+// after its events are published and marked processed, so a restart resumes
+// from the first unpublished transaction. Only one relay can hold the slot;
+// extra replicas keep retrying and take over when it is released.
+type OutboxCDCRelay struct {
+	stream     *postgres.OutboxStream
+	outboxRepo *postgres.OutboxRepository
+	kafkaProd  *kafka.Producer
+	cfg        CDCConfig
+}
+
+func NewOutboxCDCRelay(stream *postgres.OutboxStream, outboxRepo *postgres.OutboxRepository, kafkaProd *kafka.Producer, cfg CDCConfig) *OutboxCDCRelay {
+	serveMetrics()
+
+	if cfg.WorkerID == "" {
+		cfg.WorkerID = defaultWorkerID()
+	}
+	if cfg.LeaseDuration <= 0 {
+		cfg.LeaseDuration = 30 * time.Second
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = 5 * time.Second
+	}
+
+	return &OutboxCDCRelay{
+		stream:     stream,
+		outboxRepo: outboxRepo,
+		kafkaProd:  kafkaProd,
+		cfg:        cfg,
+	}
+}
+
+func (r *OutboxCDCRelay) Run(ctx context.Context) error {
+	log.Printf("OutboxCDCRelay started (Topic: %s, WorkerID: %s)", r.kafkaProd.GetTopic(), r.cfg.WorkerID)
+
+	for {
+		err := r.runStream(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		log.Printf("replication stream stopped: %v; reconnecting in %s", err, r.cfg.RetryDelay)
+		cdcReconnects.Inc()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(r.cfg.RetryDelay):
+		}
+	}
+}
+
+func (r *OutboxCDCRelay) runStream(ctx context.Context) error {
+	if err := r.stream.Connect(ctx); err != nil {
+		return err
+	}
+	defer r.stream.Close(context.Background())
+
+	// Rows inserted before the slot was created are not in the stream.
+	if err := r.catchUp(ctx); err != nil {
+		return fmt.Errorf("catch up: %w", err)
+	}
+
+	for {
+		tx, err := r.stream.Receive(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := r.publishTx(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := r.stream.Ack(ctx, tx.EndLSN); err != nil {
+			return err
+		}
+		cdcAckedLSN.Set(float64(r.stream.AckedLSN()))
+	}
+}
+
+// publishTx publishes the events of one transaction that are not processed yet.
+// Events already published are marked even if a later one fails, so the retry
+// after reconnecting skips them.
+func (r *OutboxCDCRelay) publishTx(ctx context.Context, tx *postgres.OutboxTx) error {
+	ids := make([]string, 0, len(tx.Events))
+	for _, e := range tx.Events {
+		ids = append(ids, e.ID)
+	}
+
+	pendingIDs, err := r.outboxRepo.FilterUnprocessed(ctx, ids)
+	if err != nil {
+		return err
+	}
+	pending := make(map[string]bool, len(pendingIDs))
+	for _, id := range pendingIDs {
+		pending[id] = true
+	}
+
+	var processedIDs []string
+	var publishErr error
+	for _, e := range tx.Events {
+		if !pending[e.ID] {
+			log.Printf("Skipping already processed event %s", e.ID)
+			cdcDuplicatesSkipped.Inc()
+			continue
+		}
+		if err := publishEvent(ctx, r.kafkaProd, r.cfg.Codecs, e); err != nil {
+			publishErr = fmt.Errorf("publish event %s: %w", e.ID, err)
+			break
+		}
+		processedIDs = append(processedIDs, e.ID)
+	}
+
+	if len(processedIDs) > 0 {
+		marked, err := r.outboxRepo.MarkStreamed(ctx, processedIDs)
+		if err != nil {
+			return err
+		}
+		log.Printf("Processed %d events (LSN %s)", marked, tx.EndLSN)
+	}
+
+	return publishErr
+}
+
+// catchUp claims and publishes 'new' rows left over from polling mode or
+// written while no slot existed.
+func (r *OutboxCDCRelay) catchUp(ctx context.Context) error {
+	for {
+		events, err := r.outboxRepo.FetchBatch(ctx, r.cfg.WorkerID, nil, 100, r.cfg.LeaseDuration)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		if err := r.publishClaimed(ctx, events); err != nil {
+			return err
+		}
+	}
+}
+
+func (r *OutboxCDCRelay) publishClaimed(ctx context.Context, events []*outbox.Event) error {
+	processedIDs, failedIDs := publishBatch(ctx, r.kafkaProd, r.cfg.Codecs, events)
+
+	if len(processedIDs) > 0 {
+		marked, err := r.outboxRepo.MarkProcessed(ctx, r.cfg.WorkerID, processedIDs)
+		if err != nil {
+			return err
+		}
+		checkFence("processed", len(processedIDs), marked)
+		log.Printf("Caught up %d events", marked)
+	}
+
+	if len(failedIDs) > 0 {
+		marked, err := r.outboxRepo.MarkFailed(ctx, r.cfg.WorkerID, failedIDs)
+		if err != nil {
+			log.Printf("failed to mark events as failed: %v", err)
+		} else {
+			checkFence("failed", len(failedIDs), marked)
+		}
+		return fmt.Errorf("%d events could not be published", len(failedIDs))
+	}
+
+	return nil
+}
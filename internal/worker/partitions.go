@@ -0,0 +1,132 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"project/internal/domain/outbox"
+	"project/internal/infrastructure/postgres"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var partitionsOwned = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "worker_outbox_partitions_owned",
+	Help: "The number of outbox partitions currently leased by this worker",
+})
+
+// PartitionBalancer keeps this worker's share of the outbox partitions. Every
+// live worker targets ceil(partitions / members): it renews its leases, gives
+// back partitions above the target and claims free ones below it. Ownership
+// changes are safe for ordering because FetchBatch never claims an event
+// while an earlier event of the same aggregate is still in flight.
+type PartitionBalancer struct {
+	repo     *postgres.OutboxPartitionRepository
+	workerID string
+	lease    time.Duration
+
+	mu    sync.RWMutex
+	owned []int
+}
+
+func NewPartitionBalancer(repo *postgres.OutboxPartitionRepository, workerID string, lease time.Duration) *PartitionBalancer {
+	if lease <= 0 {
+		lease = 15 * time.Second
+	}
+	return &PartitionBalancer{
+		repo:     repo,
+		workerID: workerID,
+		lease:    lease,
+	}
+}
+
+// Owned returns the partitions currently leased by this worker.
+func (b *PartitionBalancer) Owned() []int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.owned
+}
+
+// Run rebalances three times per lease until ctx is done, then releases all
+// partitions so that other workers take them over right away.
+func (b *PartitionBalancer) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.lease / 3)
+	defer ticker.Stop()
+
+	for {
+		if err := b.rebalance(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("failed to rebalance outbox partitions: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			b.setOwned(nil)
+			leaveCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := b.repo.Leave(leaveCtx, b.workerID); err != nil {
+				log.Printf("failed to release outbox partitions: %v", err)
+			}
+			cancel()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (b *PartitionBalancer) rebalance(ctx context.Context) error {
+	if err := b.repo.Heartbeat(ctx, b.workerID); err != nil {
+		return err
+	}
+
+	members, err := b.repo.LiveMembers(ctx, b.lease)
+	if err != nil {
+		return err
+	}
+	if members < 1 {
+		members = 1
+	}
+	target := (outbox.Partitions + members - 1) / members
+
+	owned, err := b.repo.Renew(ctx, b.workerID, b.lease)
+	if err != nil {
+		b.setOwned(nil)
+		return err
+	}
+	sort.Ints(owned)
+
+	switch {
+	case len(owned) > target:
+		extra := owned[target:]
+		// Stop fetching from them before the lease is given back.
+		b.setOwned(owned[:target])
+		if err := b.repo.Release(ctx, b.workerID, extra); err != nil {
+			return err
+		}
+		log.Printf("Released %d outbox partitions (members: %d, target: %d)", len(extra), members, target)
+		return nil
+	case len(owned) < target:
+		claimed, err := b.repo.Claim(ctx, b.workerID, target-len(owned), b.lease)
+		if err != nil {
+			b.setOwned(owned)
+			return err
+		}
+		if len(claimed) > 0 {
+			owned = append(owned, claimed...)
+			sort.Ints(owned)
+			log.Printf("Claimed %d outbox partitions (members: %d, target: %d)", len(claimed), members, target)
+		}
+	}
+
+	b.setOwned(owned)
+	return nil
+}
+
+func (b *PartitionBalancer) setOwned(owned []int) {
+	b.mu.Lock()
+	b.owned = owned
+	b.mu.Unlock()
+	partitionsOwned.Set(float64(len(owned)))
+}
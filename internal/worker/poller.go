@@ -2,13 +2,17 @@ package worker
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
 	"log"
 	"math/rand"
 	"net/http"
+	"os"
+	"sync"
 	"time"
 
 	domainEvent "project/internal/domain/event"
+	"project/internal/domain/outbox"
+	"project/internal/infrastructure/eventcodec"
 	"project/internal/infrastructure/kafka"
 	"project/internal/infrastructure/postgres"
 
@@ -26,118 +30,369 @@ var (
 		Name: "worker_outbox_publish_errors_total",
 		Help: "The total number of failed publish attempts",
 	})
+	leasesReclaimed = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "worker_outbox_leases_reclaimed_total",
+		Help: "The total number of outbox events returned to 'new' after their lease expired",
+	})
+	notifyWakeups = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "worker_outbox_notify_wakeups_total",
+		Help: "The total number of times the poller was woken up by an outbox notification",
+	})
+	fenceLost = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "worker_outbox_fence_lost_total",
+		Help: "The total number of events a relay could not mark because its claim had expired or been taken over",
+	}, []string{"op"})
 )
 
+// PollerConfig controls how the poller claims outbox rows.
+type PollerConfig struct {
+	WorkerID      string
+	LeaseDuration time.Duration
+	ReapInterval  time.Duration
+	// PollInterval is the safety-net tick used when no notification arrives.
+	PollInterval time.Duration
+	BatchSize    int
+	// MaxDrainBatches caps how many full batches are processed per wake-up.
+	MaxDrainBatches int
+	// MaxInFlight is how many claimed batches may be publishing at once. The
+	// next batch is claimed while earlier ones are in flight; FetchBatch skips
+	// aggregates with an event in flight, so per-aggregate order is kept.
+	MaxInFlight int
+	// PartitionLease is how long a partition stays leased without renewal.
+	PartitionLease time.Duration
+	// Codecs picks the envelope encoding of each topic; nil means JSON.
+	Codecs *eventcodec.Selector
+}
+
 type OutboxPoller struct {
-	outboxRepo *postgres.OutboxRepository
+	outboxRepo outbox.Repository
 	kafkaProd  *kafka.Producer
+	listener   *postgres.OutboxListener
+	balancer   *PartitionBalancer
+	cfg        PollerConfig
+
+	// inFlight holds one slot per batch being published.
+	inFlight chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewOutboxPoller creates a poller that relays events of the partitions it
+// leases through partitionRepo. listener is optional; without it the poller
+// only wakes up on PollInterval.
+func NewOutboxPoller(outboxRepo outbox.Repository, partitionRepo *postgres.OutboxPartitionRepository, kafkaProd *kafka.Producer, listener *postgres.OutboxListener, cfg PollerConfig) *OutboxPoller {
+	serveMetrics()
+
+	if cfg.WorkerID == "" {
+		cfg.WorkerID = defaultWorkerID()
+	}
+	if cfg.LeaseDuration <= 0 {
+		cfg.LeaseDuration = 30 * time.Second
+	}
+	if cfg.ReapInterval <= 0 {
+		cfg.ReapInterval = 10 * time.Second
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 2 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 10
+	}
+	if cfg.MaxDrainBatches <= 0 {
+		cfg.MaxDrainBatches = 10
+	}
+	if cfg.MaxInFlight <= 0 {
+		cfg.MaxInFlight = 1
+	}
+
+	return &OutboxPoller{
+		outboxRepo: outboxRepo,
+		kafkaProd:  kafkaProd,
+		listener:   listener,
+		balancer:   NewPartitionBalancer(partitionRepo, cfg.WorkerID, cfg.PartitionLease),
+		cfg:        cfg,
+		inFlight:   make(chan struct{}, cfg.MaxInFlight),
+	}
 }
 
-func NewOutboxPoller(outboxRepo *postgres.OutboxRepository, kafkaProd *kafka.Producer) *OutboxPoller {
-	// Start metrics server for worker
+// serveMetrics starts the worker metrics server.
+func serveMetrics() {
 	go func() {
 		mux := http.NewServeMux()
 		mux.Handle("/metrics", promhttp.Handler())
 		log.Println("Worker metrics listening on :9093")
 		http.ListenAndServe(":9093", mux)
 	}()
+}
 
-	return &OutboxPoller{
-		outboxRepo: outboxRepo,
-		kafkaProd:  kafkaProd,
+func defaultWorkerID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "worker"
 	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
 }
 
 func (p *OutboxPoller) Run(ctx context.Context) error {
-	ticker := time.NewTicker(2 * time.Second)
+	ticker := time.NewTicker(p.cfg.PollInterval)
 	defer ticker.Stop()
 
-	log.Printf("OutboxPoller started (Topic: %s)", p.kafkaProd.GetTopic())
+	log.Printf("OutboxPoller started (Topic: %s, WorkerID: %s, Lease: %s, Notify: %t, MaxInFlight: %d)", p.kafkaProd.GetTopic(), p.cfg.WorkerID, p.cfg.LeaseDuration, p.listener != nil, p.cfg.MaxInFlight)
+
+	go p.runReaper(ctx)
+	go p.balancer.Run(ctx)
+
+	wakeup := make(chan struct{}, 1)
+	if p.listener != nil {
+		go p.listener.Run(ctx, wakeup)
+	}
+
+	// Batches still in flight are marked before Run returns.
+	defer p.wg.Wait()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
+		case <-wakeup:
+			notifyWakeups.Inc()
+			p.drain(ctx)
+		case <-ticker.C:
+			p.drain(ctx)
+		}
+	}
+}
+
+// drain processes batches until one comes back short or MaxDrainBatches is
+// reached; the rest is left for the next wake-up.
+func (p *OutboxPoller) drain(ctx context.Context) {
+	for i := 0; i < p.cfg.MaxDrainBatches; i++ {
+		n, err := p.processBatch(ctx)
+		if err != nil {
+			log.Printf("failed to process batch: %v", err)
+			return
+		}
+		if n < p.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+// runReaper periodically returns events stranded in 'processing' by a crashed
+// worker back to 'new' so that another replica can pick them up.
+func (p *OutboxPoller) runReaper(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.ReapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
 		case <-ticker.C:
-			if err := p.processBatch(ctx); err != nil {
-				log.Printf("failed to process batch: %v", err)
+			n, err := p.outboxRepo.ReclaimExpired(ctx)
+			if err != nil {
+				log.Printf("failed to reclaim expired leases: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("Reclaimed %d outbox events with expired leases", n)
+				leasesReclaimed.Add(float64(n))
 			}
 		}
 	}
 }
 
-func (p *OutboxPoller) processBatch(ctx context.Context) error {
-	events, err := p.outboxRepo.FetchBatch(ctx, 10)
+// processBatch claims one batch and starts publishing it; the batch is marked
+// once Kafka answers. It waits for a free in-flight slot first and returns how
+// many events it claimed.
+func (p *OutboxPoller) processBatch(ctx context.Context) (int, error) {
+	partitions := p.balancer.Owned()
+	if len(partitions) == 0 {
+		return 0, nil
+	}
+
+	select {
+	case p.inFlight <- struct{}{}:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+
+	events, err := p.outboxRepo.FetchBatch(ctx, p.cfg.WorkerID, partitions, p.cfg.BatchSize, p.cfg.LeaseDuration)
 	if err != nil {
-		return err
+		<-p.inFlight
+		return 0, err
 	}
 
 	if len(events) == 0 {
-		return nil
+		<-p.inFlight
+		return 0, nil
 	}
 
-	var processedIDs []string
-	var failedIDs []string
-
 	// Simulate load (2-3s) so the publish step is observable
 	time.Sleep(2*time.Second + time.Duration(rand.Intn(1000))*time.Millisecond)
 
-	for _, e := range events {
-		log.Printf("Sending event %s to kafka...", e.ID)
+	p.wg.Add(1)
+	publishBatchAsync(ctx, p.kafkaProd, p.cfg.Codecs, events, func(processedIDs, failedIDs []string) {
+		defer p.wg.Done()
+		defer func() { <-p.inFlight }()
+
+		// Marks still land after shutdown began, so the events are not
+		// republished once their lease runs out.
+		markCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		p.markBatch(markCtx, processedIDs, failedIDs)
+	})
 
-		key := []byte(e.CorrelationID)
-		if len(key) == 0 {
-			key = []byte(e.ID)
+	return len(events), nil
+}
+
+func (p *OutboxPoller) markBatch(ctx context.Context, processedIDs, failedIDs []string) {
+	if len(processedIDs) > 0 {
+		log.Printf("Marking %d events as processed in DB...", len(processedIDs))
+		marked, err := p.outboxRepo.MarkProcessed(ctx, p.cfg.WorkerID, processedIDs)
+		if err != nil {
+			log.Printf("failed to mark events as processed: %v", err)
+		} else {
+			checkFence("processed", len(processedIDs), marked)
+			log.Printf("Processed %d events", marked)
 		}
+	}
 
-		msg := domainEvent.Message{
-			ID:            e.ID,
-			Type:          e.EventType,
-			CorrelationID: e.CorrelationID,
-			CausationID:   e.CausationID,
-			Producer:      e.Producer,
-			OccurredAt:    time.Now().UTC(),
-			Payload:       e.Payload,
+	if len(failedIDs) > 0 {
+		marked, err := p.outboxRepo.MarkFailed(ctx, p.cfg.WorkerID, failedIDs)
+		if err != nil {
+			log.Printf("failed to mark events as failed: %v", err)
+		} else {
+			checkFence("failed", len(failedIDs), marked)
 		}
+	}
+}
+
+// checkFence reports claimed events that could not be marked: their lease ran
+// out while they were being published, so they were reclaimed and another
+// relay owns them now. They were published anyway and will be again by the new
+// owner; consumers drop the copy by event-id.
+func checkFence(op string, claimed int, marked int64) {
+	if lost := int64(claimed) - marked; lost > 0 {
+		log.Printf("lost the claim on %d of %d events while marking them %s; the lease is shorter than a publish", lost, claimed, op)
+		fenceLost.WithLabelValues(op).Add(float64(lost))
+	}
+}
+
+// publishBatch sends events in one WriteMessages call and splits their ids
+// into delivered and to-be-retried.
+func publishBatch(ctx context.Context, kafkaProd *kafka.Producer, codecs *eventcodec.Selector, events []*outbox.Event) (processedIDs, failedIDs []string) {
+	done := make(chan struct{})
+	publishBatchAsync(ctx, kafkaProd, codecs, events, func(processed, failed []string) {
+		processedIDs, failedIDs = processed, failed
+		close(done)
+	})
+	<-done
+	return processedIDs, failedIDs
+}
 
-		value, err := json.Marshal(msg)
+// publishBatchAsync is publishBatch that returns once the batch is handed to
+// the producer; done receives the ids when Kafka answers.
+func publishBatchAsync(ctx context.Context, kafkaProd *kafka.Producer, codecs *eventcodec.Selector, events []*outbox.Event, done func(processedIDs, failedIDs []string)) {
+	// FetchBatch claims only the head of each aggregate, so the events of a
+	// batch never depend on each other and go out in one WriteMessages call.
+	var msgs []kafka.Message
+	var sent []*outbox.Event
+	var failedIDs []string
+
+	for _, e := range events {
+		msg, err := encodeEvent(e, kafkaProd.Route(e.EventType), codecs)
 		if err != nil {
 			log.Printf("failed to marshal event %s: %v", e.ID, err)
 			publishErrors.Inc()
 			failedIDs = append(failedIDs, e.ID)
 			continue
 		}
+		msgs = append(msgs, msg)
+		sent = append(sent, e)
+	}
 
-		// Create a timeout context for this specific send operation
-		sendCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-		err = p.kafkaProd.SendMessage(sendCtx, key, value)
+	log.Printf("Sending %d events to kafka...", len(msgs))
+	sendCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	kafkaProd.SendBatchAsync(sendCtx, msgs, func(results []error) {
 		cancel()
 
-		if err != nil {
-			log.Printf("failed to send event %s to kafka: %v", e.ID, err)
-			publishErrors.Inc()
-			failedIDs = append(failedIDs, e.ID)
-			continue
+		var processedIDs []string
+		for i, err := range results {
+			if err != nil {
+				log.Printf("failed to send event %s to kafka: %v", sent[i].ID, err)
+				publishErrors.Inc()
+				failedIDs = append(failedIDs, sent[i].ID)
+				continue
+			}
+			eventsPublished.Inc()
+			processedIDs = append(processedIDs, sent[i].ID)
 		}
+		done(processedIDs, failedIDs)
+	})
+}
 
-		log.Printf("Successfully sent event %s", e.ID)
-		eventsPublished.Inc()
-		processedIDs = append(processedIDs, e.ID)
+// encodeEvent wraps an outbox row into the event envelope, keyed by
+// correlation id so that one saga stays on one partition. The message depends
+// only on the row, so a republished event is an exact copy of the first one.
+// The envelope is encoded with the codec of topic, named in the content-type header.
+func encodeEvent(e *outbox.Event, topic string, codecs *eventcodec.Selector) (kafka.Message, error) {
+	key := []byte(e.CorrelationID)
+	if len(key) == 0 {
+		key = []byte(e.ID)
 	}
 
-	if len(processedIDs) > 0 {
-		log.Printf("Marking %d events as processed in DB...", len(processedIDs))
-		if err := p.outboxRepo.MarkProcessed(ctx, processedIDs); err != nil {
-			return err
-		}
-		log.Printf("Processed %d events", len(processedIDs))
+	msg := domainEvent.Message{
+		ID:            e.ID,
+		Type:          e.EventType,
+		CorrelationID: e.CorrelationID,
+		CausationID:   e.CausationID,
+		Producer:      e.Producer,
+		SchemaVersion: e.SchemaVersion,
+		OccurredAt:    e.CreatedAt.UTC(),
+		Payload:       e.Payload,
+	}
+	if e.CreatedAt.IsZero() {
+		msg.OccurredAt = time.Now().UTC()
 	}
 
-	if len(failedIDs) > 0 {
-		if err := p.outboxRepo.MarkFailed(ctx, failedIDs); err != nil {
-			log.Printf("failed to mark events as failed: %v", err)
-		}
+	codec := codecs.ForTopic(topic)
+	value, err := codec.Encode(msg)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+
+	headers := msg.Headers()
+	headers[domainEvent.HeaderContentType] = codec.ContentType()
+
+	return kafka.Message{
+		Topic:   topic,
+		Key:     key,
+		Value:   value,
+		Headers: headers,
+	}, nil
+}
+
+// publishEvent sends a single outbox row to Kafka.
+func publishEvent(ctx context.Context, kafkaProd *kafka.Producer, codecs *eventcodec.Selector, e *outbox.Event) error {
+	log.Printf("Sending event %s to kafka...", e.ID)
+
+	msg, err := encodeEvent(e, kafkaProd.Route(e.EventType), codecs)
+	if err != nil {
+		publishErrors.Inc()
+		return err
+	}
+
+	// Create a timeout context for this specific send operation
+	sendCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	err = kafkaProd.SendBatch(sendCtx, []kafka.Message{msg})[0]
+	cancel()
+
+	if err != nil {
+		publishErrors.Inc()
+		return err
 	}
 
+	log.Printf("Successfully sent event %s", e.ID)
+	eventsPublished.Inc()
 	return nil
 }
@@ -0,0 +1,151 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	domainEvent "project/internal/domain/event"
+	"project/internal/domain/order"
+	"project/internal/domain/outbox"
+	"project/internal/infrastructure/postgres"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var sagaTimeouts = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "saga_watchdog_timeouts_total",
+	Help: "The total number of timeout events emitted by the saga watchdog",
+}, []string{"status", "event_type"})
+
+// WatchdogStep is a saga step with a deadline: an order that stays in Status
+// for longer than Deadline gets EventType emitted through the outbox.
+type WatchdogStep struct {
+	Status    string
+	Deadline  time.Duration
+	EventType string
+}
+
+type WatchdogConfig struct {
+	Steps        []WatchdogStep
+	ScanInterval time.Duration
+	BatchSize    int
+}
+
+// SagaWatchdog scans for orders stuck in a saga step and emits timeout events
+// that make the participants cancel or compensate.
+type SagaWatchdog struct {
+	txManager   postgres.Transactor
+	orderRepo   *postgres.OrderRepository
+	outboxRepo  *postgres.OutboxRepository
+	timeoutRepo *postgres.SagaTimeoutRepository
+	cfg         WatchdogConfig
+}
+
+func NewSagaWatchdog(
+	txManager postgres.Transactor,
+	orderRepo *postgres.OrderRepository,
+	outboxRepo *postgres.OutboxRepository,
+	timeoutRepo *postgres.SagaTimeoutRepository,
+	cfg WatchdogConfig,
+) *SagaWatchdog {
+	if cfg.ScanInterval <= 0 {
+		cfg.ScanInterval = 15 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+
+	return &SagaWatchdog{
+		txManager:   txManager,
+		orderRepo:   orderRepo,
+		outboxRepo:  outboxRepo,
+		timeoutRepo: timeoutRepo,
+		cfg:         cfg,
+	}
+}
+
+func (w *SagaWatchdog) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.cfg.ScanInterval)
+	defer ticker.Stop()
+
+	log.Printf("SagaWatchdog started (Steps: %d, Interval: %s)", len(w.cfg.Steps), w.cfg.ScanInterval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			for _, step := range w.cfg.Steps {
+				if err := w.scan(ctx, step); err != nil {
+					log.Printf("failed to scan %s orders: %v", step.Status, err)
+				}
+			}
+		}
+	}
+}
+
+func (w *SagaWatchdog) scan(ctx context.Context, step WatchdogStep) error {
+	if step.Deadline <= 0 {
+		return nil
+	}
+
+	orders, err := w.orderRepo.ListStuck(ctx, step.Status, step.Deadline, w.cfg.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range orders {
+		if err := w.timeout(ctx, step, o); err != nil {
+			log.Printf("failed to time out order %s: %v", o.ID, err)
+		}
+	}
+	return nil
+}
+
+// timeout registers the timeout and writes the event in one transaction, so an
+// order stuck in a step is reported exactly once even with several watchdogs.
+func (w *SagaWatchdog) timeout(ctx context.Context, step WatchdogStep, o *order.Order) error {
+	payload, err := json.Marshal(domainEvent.SagaTimedOut{
+		OrderID:    o.ID,
+		Status:     o.Status,
+		StuckSince: o.UpdatedAt,
+		Deadline:   step.Deadline.String(),
+		SagaMode:   o.SagaMode,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal %s payload: %w", step.EventType, err)
+	}
+
+	emitted := false
+	err = w.txManager.WithinTransaction(ctx, func(txCtx context.Context) error {
+		isNew, err := w.timeoutRepo.Register(txCtx, o.ID, step.Status, step.EventType, o.UpdatedAt)
+		if err != nil || !isNew {
+			return err
+		}
+
+		emitted = true
+		return w.outboxRepo.Create(txCtx, &outbox.Event{
+			ID:            uuid.New().String(),
+			EventType:     step.EventType,
+			Payload:       payload,
+			Status:        "new",
+			CorrelationID: o.ID,
+			Producer:      "saga-watchdog",
+			CreatedAt:     time.Now(),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if emitted {
+		sagaTimeouts.WithLabelValues(step.Status, step.EventType).Inc()
+		log.Printf("Order %s stuck in %s since %s: emitted %s", o.ID, o.Status, o.UpdatedAt.Format(time.RFC3339), step.EventType)
+	}
+	return nil
+}
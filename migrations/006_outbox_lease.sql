@@ -0,0 +1,9 @@
+-- Lease-based outbox claims.
+-- A worker that claims a row records its id and a lease deadline; rows whose
+-- lease expired while still 'processing' are returned to 'new' by the reaper.
+
+ALTER TABLE outbox
+  ADD COLUMN IF NOT EXISTS claimed_by TEXT,
+  ADD COLUMN IF NOT EXISTS lease_expires_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_status_lease_expires_at ON outbox(status, lease_expires_at);
@@ -0,0 +1,25 @@
+-- Dead letters: Kafka messages a consumer gave up on after exhausting retries.
+-- The raw message is also published to the dead-letter topic; this table lets
+-- operators see what was lost without reading the topic.
+
+CREATE TABLE IF NOT EXISTS dead_letters (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  consumer TEXT NOT NULL,
+  topic TEXT NOT NULL,
+  kafka_partition INT NOT NULL,
+  kafka_offset BIGINT NOT NULL,
+  message_key BYTEA,
+  payload BYTEA NOT NULL,
+  event_id TEXT,
+  event_type TEXT,
+  correlation_id TEXT,
+  error TEXT NOT NULL,
+  attempts INT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'new', -- new, replayed, discarded
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dead_letters_consumer_position ON dead_letters(consumer, topic, kafka_partition, kafka_offset);
+CREATE INDEX IF NOT EXISTS idx_dead_letters_status_created_at ON dead_letters(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_dead_letters_correlation_id ON dead_letters(correlation_id);
@@ -0,0 +1,26 @@
+-- Payment authorization policy: failure reason on payments and a per-user ledger.
+
+ALTER TABLE payments
+  ADD COLUMN IF NOT EXISTS failure_reason TEXT;
+
+-- Local ledger of the payment service. Balance = SUM(amount):
+-- credits are positive, authorization holds are negative.
+CREATE TABLE IF NOT EXISTS payment_ledger (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id),
+  order_id UUID REFERENCES orders(id),
+  kind TEXT NOT NULL, -- DEPOSIT, HOLD
+  amount DECIMAL(12, 2) NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_ledger_user_id ON payment_ledger(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_ledger_order_kind ON payment_ledger(order_id, kind) WHERE order_id IS NOT NULL;
+
+-- Opening balance for the demo users (applied once).
+INSERT INTO payment_ledger (user_id, kind, amount)
+SELECT u.id, 'DEPOSIT', 50000
+FROM users u
+WHERE NOT EXISTS (
+  SELECT 1 FROM payment_ledger l WHERE l.user_id = u.id AND l.kind = 'DEPOSIT' AND l.order_id IS NULL
+);
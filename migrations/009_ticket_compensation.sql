@@ -0,0 +1,8 @@
+-- Ticket failure and payment compensation.
+
+ALTER TABLE tickets
+  ADD COLUMN IF NOT EXISTS failure_reason TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_tickets_flight ON tickets(from_city, to_city, travel_date, travel_time, airline) WHERE status = 'ISSUED';
+
+-- payment_ledger.kind gains RELEASE: a voided/refunded payment returns its HOLD.
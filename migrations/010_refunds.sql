@@ -0,0 +1,12 @@
+-- Refunds issued by the payment service in response to RefundInitiated.
+
+CREATE TABLE IF NOT EXISTS refunds (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  payment_id UUID NOT NULL REFERENCES payments(id),
+  order_id UUID NOT NULL REFERENCES orders(id),
+  amount DECIMAL(10, 2) NOT NULL,
+  reason TEXT,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refunds_order_id ON refunds(order_id);
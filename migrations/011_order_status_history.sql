@@ -0,0 +1,14 @@
+-- Every order status change, written in the same transaction as the change.
+
+CREATE TABLE IF NOT EXISTS order_status_history (
+  id BIGSERIAL PRIMARY KEY,
+  order_id UUID NOT NULL REFERENCES orders(id),
+  from_status TEXT, -- NULL for the initial status
+  to_status TEXT NOT NULL,
+  changed_by TEXT NOT NULL, -- service that made the change
+  event_type TEXT, -- event that caused the change, if any
+  causation_id UUID, -- id of that event
+  changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_status_history_order_changed_at ON order_status_history(order_id, changed_at);
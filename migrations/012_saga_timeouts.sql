@@ -0,0 +1,12 @@
+-- Saga watchdog: one timeout per order and stuck status.
+
+CREATE TABLE IF NOT EXISTS saga_timeouts (
+  order_id UUID NOT NULL REFERENCES orders(id),
+  status TEXT NOT NULL, -- status the order was stuck in
+  event_type TEXT NOT NULL, -- timeout event emitted
+  stuck_since TIMESTAMP WITH TIME ZONE NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (order_id, status)
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_status_updated_at ON orders(status, updated_at);
@@ -0,0 +1,24 @@
+-- Orchestrated saga mode. The booking flow of each order runs either as
+-- choreography (participants react to each other's events) or is driven by
+-- the orchestrator through commands.
+
+ALTER TABLE orders
+  ADD COLUMN IF NOT EXISTS saga_mode TEXT NOT NULL DEFAULT 'choreography';
+
+-- Orchestrator state: one instance per order and flow.
+CREATE TABLE IF NOT EXISTS saga_instances (
+  id UUID PRIMARY KEY,
+  flow TEXT NOT NULL, -- e.g. booking
+  order_id UUID NOT NULL REFERENCES orders(id),
+  step TEXT NOT NULL, -- AUTHORIZE_PAYMENT, ISSUE_TICKET, VOID_PAYMENT, DONE
+  state TEXT NOT NULL, -- RUNNING, COMPENSATING, COMPLETED, COMPENSATED, FAILED
+  retries INT NOT NULL DEFAULT 0, -- resends of the current command
+  command TEXT, -- last command sent, resent when the deadline passes
+  command_payload JSONB,
+  deadline_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saga_instances_flow_order ON saga_instances(flow, order_id);
+CREATE INDEX IF NOT EXISTS idx_saga_instances_state_deadline ON saga_instances(state, deadline_at);
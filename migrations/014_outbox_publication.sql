@@ -0,0 +1,11 @@
+-- Publication for the cdc outbox relay (OUTBOX_MODE=cdc).
+-- Only inserts are streamed; the relay creates its logical replication slot
+-- on first start. Requires wal_level=logical.
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_publication WHERE pubname = 'outbox_pub') THEN
+    CREATE PUBLICATION outbox_pub FOR TABLE outbox WITH (publish = 'insert');
+  END IF;
+END
+$$;
@@ -0,0 +1,7 @@
+-- Per-aggregate ordering in the outbox relay.
+-- FetchBatch only claims the oldest unpublished event of each correlation_id;
+-- this index serves that lookup over the rows still waiting to be published.
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending_aggregate
+  ON outbox ((COALESCE(correlation_id::text, id::text)), created_at, id)
+  WHERE status IN ('new', 'processing');
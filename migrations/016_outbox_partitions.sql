@@ -0,0 +1,27 @@
+-- Partitioned outbox relay.
+-- Every row gets a partition derived from its aggregate (correlation_id, or the
+-- event id when there is none). Worker replicas lease whole partitions, so all
+-- events of one order are relayed by a single worker at a time.
+
+ALTER TABLE outbox
+  ADD COLUMN IF NOT EXISTS partition_key INT
+    GENERATED ALWAYS AS ((hashtext(COALESCE(correlation_id::text, id::text)) & 2147483647) % 64) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_partition_status_created_at ON outbox(partition_key, status, created_at);
+
+-- One row per partition; owner holds it until lease_expires_at.
+CREATE TABLE IF NOT EXISTS outbox_partitions (
+  partition INT PRIMARY KEY,
+  owner TEXT,
+  lease_expires_at TIMESTAMP WITH TIME ZONE
+);
+
+INSERT INTO outbox_partitions (partition)
+SELECT generate_series(0, 63)
+ON CONFLICT (partition) DO NOTHING;
+
+-- Live relay workers; the partitions are shared evenly between them.
+CREATE TABLE IF NOT EXISTS outbox_relay_members (
+  worker_id TEXT PRIMARY KEY,
+  heartbeat_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
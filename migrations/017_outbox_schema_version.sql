@@ -0,0 +1,7 @@
+-- Versioned event payloads.
+-- The version of the payload schema (see internal/domain/event catalog) is
+-- stored with the row so the relay publishes it in the envelope even if the
+-- catalog moved on before the row was sent. Rows written before the catalog
+-- existed are version 1.
+
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS schema_version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,6 @@
+-- Event codecs.
+-- Dead letters keep the raw message value; content_type records the codec it
+-- was written with (the content-type header), so replay and the DLQ API can
+-- decode it. Messages from before the header are JSON.
+
+ALTER TABLE dead_letters ADD COLUMN IF NOT EXISTS content_type TEXT NOT NULL DEFAULT 'application/json';
@@ -0,0 +1,44 @@
+-- WatchOrder (gRPC) wake-ups.
+-- Every status change of an order and every inbox/outbox transition of its
+-- saga is signalled on the order_changes channel with the order id as payload.
+-- The triggers catch all writers (api, consumers, relay) and, like any NOTIFY,
+-- deliver on commit only.
+
+CREATE OR REPLACE FUNCTION notify_order_change() RETURNS trigger AS $$
+DECLARE
+  order_id TEXT;
+BEGIN
+  IF TG_TABLE_NAME = 'order_status_history' THEN
+    order_id := NEW.order_id::text;
+  ELSE
+    order_id := NEW.correlation_id::text;
+  END IF;
+
+  IF order_id IS NOT NULL THEN
+    PERFORM pg_notify('order_changes', order_id);
+  END IF;
+  RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS order_status_history_notify ON order_status_history;
+CREATE TRIGGER order_status_history_notify
+  AFTER INSERT ON order_status_history
+  FOR EACH ROW EXECUTE FUNCTION notify_order_change();
+
+DROP TRIGGER IF EXISTS inbox_events_notify ON inbox_events;
+CREATE TRIGGER inbox_events_notify
+  AFTER INSERT ON inbox_events
+  FOR EACH ROW EXECUTE FUNCTION notify_order_change();
+
+DROP TRIGGER IF EXISTS outbox_insert_notify ON outbox;
+CREATE TRIGGER outbox_insert_notify
+  AFTER INSERT ON outbox
+  FOR EACH ROW EXECUTE FUNCTION notify_order_change();
+
+-- Lease renewals do not change the status and are not signalled.
+DROP TRIGGER IF EXISTS outbox_status_notify ON outbox;
+CREATE TRIGGER outbox_status_notify
+  AFTER UPDATE OF status ON outbox
+  FOR EACH ROW WHEN (OLD.status IS DISTINCT FROM NEW.status)
+  EXECUTE FUNCTION notify_order_change();
@@ -0,0 +1,12 @@
+-- Write order of outbox events.
+-- created_at cannot order the events of one aggregate: events written in one
+-- transaction can carry the same timestamp. seq is assigned on insert, in the
+-- order the rows are written, and FetchBatch picks the head of each aggregate
+-- by it. Existing rows are numbered in table order.
+
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS seq BIGSERIAL;
+
+DROP INDEX IF EXISTS idx_outbox_pending_aggregate;
+CREATE INDEX IF NOT EXISTS idx_outbox_pending_aggregate_seq
+  ON outbox ((COALESCE(correlation_id::text, id::text)), seq)
+  WHERE status IN ('new', 'processing');
@@ -34,17 +34,21 @@ for i in {1..30}; do
     sleep 1
 done
 
-docker-compose -p web_app exec -T postgres psql -U user -d wb_tech -f /docker-entrypoint-initdb.d/005_saga_choreography.sql >/dev/null || {
-    echo -e "${RED}Failed to apply migrations (005_saga_choreography.sql)${NC}";
-    exit 1;
-}
-
-# Force kill any process on port 8080 to avoid "address already in use" errors
-if lsof -ti :8080 >/dev/null; then
-    echo -e "${RED}Port 8080 is in use, forcing cleanup...${NC}"
-    lsof -ti :8080 | xargs kill -9
-    sleep 1
-fi
+for migration in 005_saga_choreography.sql 006_outbox_lease.sql 007_dead_letters.sql 008_payment_policy.sql 009_ticket_compensation.sql 010_refunds.sql 011_order_status_history.sql 012_saga_timeouts.sql 013_saga_orchestration.sql 014_outbox_publication.sql 015_outbox_aggregate_order.sql 016_outbox_partitions.sql 017_outbox_schema_version.sql 018_dead_letter_content_type.sql 019_order_watch.sql 020_outbox_seq.sql; do
+    docker-compose -p web_app exec -T postgres psql -U user -d wb_tech -f /docker-entrypoint-initdb.d/$migration >/dev/null || {
+        echo -e "${RED}Failed to apply migrations ($migration)${NC}";
+        exit 1;
+    }
+done
+
+# Force kill any process on the API ports (HTTP, gRPC) to avoid "address already in use" errors
+for port in 8080 50051; do
+    if lsof -ti :$port >/dev/null; then
+        echo -e "${RED}Port $port is in use, forcing cleanup...${NC}"
+        lsof -ti :$port | xargs kill -9
+        sleep 1
+    fi
+done
 
 
 # 3. Start Backend Services
@@ -101,6 +105,7 @@ echo -e "${GREEN}Frontend started (PID: $FRONTEND_PID)${NC}"
 echo -e "${BLUE}=== System Ready ===${NC}"
 echo -e "Frontend: ${GREEN}http://localhost:5173${NC}"
 echo -e "API:      ${GREEN}http://localhost:8080${NC}"
+echo -e "gRPC:     ${GREEN}localhost:50051${NC}"
 echo -e "Grafana:  ${GREEN}http://localhost:3000${NC}"
 echo -e "Logs available in: logs/ directory"
 echo -e "Run 'make down' to stop everything."